@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports the sets as profiles of other applications",
+	Long:  `This command writes an entry for each set and shortcut into the configuration of another application, such as Windows Terminal.`,
+}
+
+var exportWTCmd = &cobra.Command{
+	Use:   "wt",
+	Short: "Writes a Windows Terminal profile fragment",
+	Long: `This command writes a Windows Terminal JSON fragment with a profile for each set and shortcut.
+Re-running it updates the profiles in place, they keep the same GUIDs.`,
+	// errors here are about the configuration or the output file, not the command line
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		executable, err := utils.LauncherExecutable(cmd.Flag("executable").Value.String())
+		if err != nil {
+			return err
+		}
+		profiles, warnings, err := utils.WindowsTerminalProfiles(executable)
+		if err != nil {
+			l.Logger.Error("Failed to build Windows Terminal profiles", "error", err)
+			return err
+		}
+		for _, warning := range warnings {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Warning:", warning)
+		}
+		if print, _ := cmd.Flags().GetBool("print"); print {
+			content, err := json.MarshalIndent(map[string]interface{}{"profiles": profiles}, "", "    ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(content))
+			return nil
+		}
+		path := cmd.Flag("output").Value.String()
+		if path == "" {
+			if path, err = utils.WindowsTerminalFragmentPath(); err != nil {
+				return err
+			}
+		}
+		result, err := utils.WriteWindowsTerminalFragment(path, executable, profiles)
+		if err != nil {
+			l.Logger.Error("Failed to write Windows Terminal fragment", "error", err)
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), result)
+		return nil
+	},
+}
+
+var exportVSCodeCmd = &cobra.Command{
+	Use:   "vscode [settings.json]",
+	Short: "Adds VS Code terminal profiles for the sets",
+	Long: `This command merges a terminal profile for each set and shortcut into terminal.integrated.profiles.<os>
+of a VS Code settings.json, the user settings by default. Comments and other settings are kept.`,
+	Args: cobra.MaximumNArgs(1),
+	// errors here are about the configuration or the settings file, not the command line
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		platform, err := utils.VSCodePlatform(cmd.Flag("os").Value.String())
+		if err != nil {
+			return err
+		}
+		executable, err := utils.LauncherExecutable(cmd.Flag("executable").Value.String())
+		if err != nil {
+			return err
+		}
+		profiles, warnings, err := utils.VSCodeTerminalProfiles(executable)
+		if err != nil {
+			l.Logger.Error("Failed to build VS Code terminal profiles", "error", err)
+			return err
+		}
+		for _, warning := range warnings {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Warning:", warning)
+		}
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		} else if path, err = utils.VSCodeSettingsPath(); err != nil {
+			return err
+		}
+		result, err := utils.WriteVSCodeSettings(path, platform, executable, profiles)
+		if err != nil {
+			l.Logger.Error("Failed to write VS Code settings", "error", err)
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), result)
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.PersistentFlags().String("executable", "", "Launcher path written into the profiles, defaults to export.executable or this executable")
+	exportWTCmd.Flags().StringP("output", "o", "", "Fragment file to write, defaults to export.wt.path or the Windows Terminal fragments folder")
+	exportWTCmd.Flags().Bool("print", false, "Print the generated profiles without writing the fragment")
+	exportVSCodeCmd.Flags().String("os", runtime.GOOS, "Platform of the profiles: windows, linux or osx")
+	exportCmd.AddCommand(exportWTCmd)
+	exportCmd.AddCommand(exportVSCodeCmd)
+	rootCmd.AddCommand(exportCmd)
+}
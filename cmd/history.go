@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Lists and relaunches recent launches",
+	Long:  `This command lists recent launches, newest first, and relaunches the same shell and profiles.`,
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists recent launches, newest first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := utils.LoadHistory()
+		if err != nil {
+			l.Logger.Error("Failed to load history", "error", err)
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No launches recorded")
+			return nil
+		}
+		for i, entry := range entries {
+			fmt.Fprintf(cmd.OutOrStdout(), "%3d  %s  [%s]\n", i+1, entry.Summary(), entry.Outcome())
+		}
+		return nil
+	},
+}
+
+var historyRelaunchCmd = &cobra.Command{
+	Use:   "relaunch <n>",
+	Short: "Relaunches entry n from the history list",
+	Args:  cobra.ExactArgs(1),
+	// errors here are about the entry, not the command line
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid history entry %q", args[0])
+		}
+		entries, err := utils.LoadHistory()
+		if err != nil {
+			l.Logger.Error("Failed to load history", "error", err)
+			return err
+		}
+		if n < 1 || n > len(entries) {
+			return fmt.Errorf("history entry %d does not exist, there are %d entries", n, len(entries))
+		}
+		req, warnings, err := utils.RelaunchRequest(entries[n-1])
+		if err != nil {
+			l.Logger.Error("Failed to relaunch", "error", err)
+			return err
+		}
+		utils.WriteLaunchSummary(cmd.OutOrStdout(), req, warnings)
+		result, err := deps.Launcher.Launch(req)
+		if err != nil {
+			l.Logger.Error("Failed to relaunch", "error", err)
+			if req.Mode == launcher.ModeInline {
+				exitCode = launcher.ExitCode(err)
+				return nil
+			}
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", req.Shell, result)
+		return nil
+	},
+}
+
+var historyClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Removes all recorded launches",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := utils.ClearHistory(); err != nil {
+			l.Logger.Error("Failed to clear history", "error", err)
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "History cleared")
+		return nil
+	},
+}
+
+func init() {
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyRelaunchCmd)
+	historyCmd.AddCommand(historyClearCmd)
+	rootCmd.AddCommand(historyCmd)
+}
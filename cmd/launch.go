@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
+)
+
+var launchCmd = &cobra.Command{
+	Use:   "launch <set-name>",
+	Short: "Launches a named profile set",
+	Long:  `This command launches the profiles of a set from the configuration in the shell of the set.`,
+	Args:  cobra.ExactArgs(1),
+	// errors here are about the set, not the command line
+	SilenceUsage: true,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		sets, err := utils.LoadSets()
+		if err != nil || len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var names []string
+		for _, set := range sets {
+			if strings.HasPrefix(strings.ToLower(set.Name), strings.ToLower(toComplete)) {
+				names = append(names, set.Name)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		l.Logger.Info("Launching set", "set", args[0])
+		set, err := utils.FindSet(args[0])
+		if err != nil {
+			l.Logger.Error("Failed to find set", "error", err)
+			return err
+		}
+		req, warnings, err := utils.SetLaunchRequest(set)
+		if err != nil {
+			l.Logger.Error("Failed to prepare set", "error", err)
+			return err
+		}
+		if print, _ := cmd.Flags().GetBool("print"); print {
+			fmt.Fprint(cmd.OutOrStdout(), req.Script)
+			return nil
+		}
+		if req.Mode, err = launchTarget(cmd, req.Mode); err != nil {
+			return err
+		}
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			plan, err := launcher.NewPlan(req)
+			if err != nil {
+				return err
+			}
+			plan.Warnings = append(plan.Warnings, warnings...)
+			return writeDryRun(cmd, plan)
+		}
+		req.Wait, _ = cmd.Flags().GetBool("wait")
+		utils.WriteLaunchSummary(cmd.OutOrStdout(), req, warnings)
+		result, err := deps.Launcher.Launch(req)
+		if err != nil {
+			l.Logger.Error("Failed to launch set", "error", err)
+			if req.Mode == launcher.ModeInline {
+				exitCode = launcher.ExitCode(err)
+				return nil
+			}
+			var earlyErr *launcher.EarlyExitError
+			if errors.As(err, &earlyErr) {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+				exitCode = launcher.ExitCode(err)
+				return nil
+			}
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", req.Shell, result)
+		if result.Waited && result.ExitCode > 0 {
+			exitCode = result.ExitCode
+		}
+		return nil
+	},
+}
+
+// launchTarget returns the target of the --target flag, inline for --inline, or the fallback.
+// The tmux targets need the launcher to run inside tmux.
+func launchTarget(cmd *cobra.Command, fallback launcher.Mode) (launcher.Mode, error) {
+	mode := fallback
+	if target := cmd.Flag("target").Value.String(); target != "" {
+		var err error
+		if mode, err = launcher.ParseMode(target); err != nil {
+			return "", err
+		}
+	} else if inline, _ := cmd.Flags().GetBool("inline"); inline {
+		mode = launcher.ModeInline
+	}
+	if mode.Tmux() && !launcher.InTmux() {
+		return "", fmt.Errorf("target %s needs the launcher to run inside a tmux session", mode)
+	}
+	return mode, nil
+}
+
+// writeDryRun prints what a command would do, as JSON with --json.
+func writeDryRun(cmd *cobra.Command, plan fmt.Stringer) error {
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		// scripts are full of < and >, keep them readable
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plan)
+	}
+	fmt.Fprint(cmd.OutOrStdout(), plan)
+	return nil
+}
+
+func init() {
+	launchCmd.Flags().Bool("print", false, "Print the merged script that would be launched without launching it")
+	launchCmd.Flags().Bool("inline", false, "Run the shell in the current terminal and exit with its exit code")
+	launchCmd.Flags().String("target", "", "Where to open the shell: window, inline, tmux or tmux-split")
+	launchCmd.Flags().Bool("dry-run", false, "Print the shell, arguments, environment, working directory and script without launching")
+	launchCmd.Flags().Bool("json", false, "Print the dry run as JSON")
+	launchCmd.Flags().Bool("wait", false, "Wait for the shell window to close and report how long it ran")
+	rootCmd.AddCommand(launchCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	"github.com/spf13/cobra"
+)
+
+func TestLaunchTarget(t *testing.T) {
+	tests := []struct {
+		args     []string
+		fallback launcher.Mode
+		inTmux   bool
+		want     launcher.Mode
+		err      string
+	}{
+		{nil, launcher.ModeWindow, false, launcher.ModeWindow, ""},
+		{[]string{"--inline"}, launcher.ModeWindow, false, launcher.ModeInline, ""},
+		{[]string{"--target", "window", "--inline"}, launcher.ModeInline, false, launcher.ModeWindow, ""},
+		{[]string{"--target", "tmux"}, launcher.ModeWindow, true, launcher.ModeTmux, ""},
+		{[]string{"--target", "tmux-split"}, launcher.ModeWindow, true, launcher.ModeTmuxSplit, ""},
+		{[]string{"--target", "tmux"}, launcher.ModeWindow, false, "", "inside a tmux session"},
+		{[]string{"--target", "tmux-split"}, launcher.ModeWindow, false, "", "inside a tmux session"},
+		// a set saved with a tmux target is refused the same way
+		{nil, launcher.ModeTmux, false, "", "inside a tmux session"},
+		{[]string{"--target", "tab"}, launcher.ModeWindow, true, "", "tab"},
+	}
+	for _, tt := range tests {
+		tmux := ""
+		if tt.inTmux {
+			tmux = "/tmp/tmux-1000/default,1,0"
+		}
+		t.Setenv("TMUX", tmux)
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("inline", false, "")
+		cmd.Flags().String("target", "", "")
+		if err := cmd.ParseFlags(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		got, err := launchTarget(cmd, tt.fallback)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("launchTarget(%v, %s) = %s, %v, want an error containing %q", tt.args, tt.fallback, got, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("launchTarget(%v, %s) = %s, %v, want %s", tt.args, tt.fallback, got, err, tt.want)
+		}
+	}
+}
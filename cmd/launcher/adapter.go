@@ -0,0 +1,175 @@
+package launcher
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+)
+
+// Shell kinds, selecting the adapter a shell is launched with.
+const (
+	KindPowerShell = "powershell"
+	KindBash       = "bash"
+	KindZsh        = "zsh"
+)
+
+// Adapter describes how a kind of shell loads the generated script and keeps the session open afterwards.
+type Adapter interface {
+	Kind() string
+	// ProfileExtension is the file name suffix of the profiles the shell runs, such as .Profile.ps1.
+	ProfileExtension() string
+	// ScriptName is the file name pattern of the generated script, see WriteScriptAs.
+	ScriptName() string
+	// DefaultArgs are passed to the shell when a request has no arguments.
+	DefaultArgs() []string
+	// ScriptArgs returns the arguments that load the script into an interactive session.
+	ScriptArgs(args []string, scriptPath string) []string
+	// ScriptEnv returns the environment the shell needs to find the script.
+	ScriptEnv(scriptPath string) []string
+	// SelfDelete returns the statement that removes the generated script once the shell has loaded it.
+	SelfDelete() string
+	// CaptureErrors returns the statements around the profiles that copy their errors to the file named by
+	// ErrorLogEnv, so a shell that exits while loading them can be reported with its errors.
+	CaptureErrors() (start string, end string)
+	// CommandArgs returns the arguments that run a single command and exit.
+	CommandArgs(command string) ([]string, error)
+	// VersionCommand prints the shell version, and the edition on a second line when the shell has one.
+	VersionCommand() string
+}
+
+var adapters = map[string]Adapter{
+	KindPowerShell: powerShellAdapter{},
+	KindBash:       bashAdapter{},
+	KindZsh:        zshAdapter{},
+}
+
+// AdapterFor returns the adapter of a shell kind, PowerShell when the kind is empty or unknown.
+func AdapterFor(kind string) Adapter {
+	if adapter, ok := adapters[strings.ToLower(strings.TrimSpace(kind))]; ok {
+		return adapter
+	}
+	return adapters[KindPowerShell]
+}
+
+// IsProfileFile reports whether the file name is a profile of any shell kind.
+func IsProfileFile(name string) bool {
+	for _, adapter := range adapters {
+		if strings.HasSuffix(name, adapter.ProfileExtension()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfileKind returns the shell kind of a profile file name, empty when it is not a profile.
+func ProfileKind(name string) string {
+	for kind, adapter := range adapters {
+		if strings.HasSuffix(name, adapter.ProfileExtension()) {
+			return kind
+		}
+	}
+	return ""
+}
+
+type powerShellAdapter struct{}
+
+func (powerShellAdapter) Kind() string             { return KindPowerShell }
+func (powerShellAdapter) ProfileExtension() string { return ".Profile.ps1" }
+func (powerShellAdapter) ScriptName() string       { return "launch_*.ps1" }
+func (powerShellAdapter) DefaultArgs() []string    { return DefaultArgs }
+func (powerShellAdapter) ScriptArgs(args []string, scriptPath string) []string {
+	return append(append([]string{}, args...), "-File", scriptPath)
+}
+func (powerShellAdapter) ScriptEnv(string) []string { return nil }
+
+// the whole script is parsed before it runs, so it can remove itself straight away
+func (powerShellAdapter) SelfDelete() string {
+	return "if ($PSCommandPath) { Remove-Item -LiteralPath $PSCommandPath -Force -ErrorAction SilentlyContinue }"
+}
+
+// the trap writes the error to the log and PowerShell still shows it and carries on with the next statement
+func (powerShellAdapter) CaptureErrors() (string, string) {
+	start := "$__launcherErrors = $env:" + ErrorLogEnv + "; Remove-Item Env:" + ErrorLogEnv + " -ErrorAction SilentlyContinue\n" +
+		"trap { if ($__launcherErrors) { $_ | Out-String | Add-Content -LiteralPath $__launcherErrors } }"
+	return start, ""
+}
+func (powerShellAdapter) CommandArgs(command string) ([]string, error) {
+	encoded, err := EncodePowerShellCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"-NoLogo", "-NoProfile", "-NonInteractive", "-EncodedCommand", encoded}, nil
+}
+func (powerShellAdapter) VersionCommand() string {
+	return "$PSVersionTable.PSVersion.ToString(); $PSVersionTable.PSEdition"
+}
+
+// EncodePowerShellCommand encodes a command for -EncodedCommand, base64 of its UTF-16LE bytes.
+func EncodePowerShellCommand(command string) (string, error) {
+	buf := new(bytes.Buffer)
+	for _, r := range utf16.Encode([]rune(command)) {
+		if err := binary.Write(buf, binary.LittleEndian, r); err != nil {
+			return "", fmt.Errorf("failed to encode command to UTF-16LE: %v", err)
+		}
+	}
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	if encoded == "" {
+		return "", fmt.Errorf("failed to encode command")
+	}
+	return encoded, nil
+}
+
+// bashAdapter loads the script as the rc file of an interactive bash, in place of ~/.bashrc.
+type bashAdapter struct{}
+
+func (bashAdapter) Kind() string             { return KindBash }
+func (bashAdapter) ProfileExtension() string { return ".Profile.sh" }
+func (bashAdapter) ScriptName() string       { return "launch_*.sh" }
+func (bashAdapter) DefaultArgs() []string    { return []string{"--noprofile"} }
+
+// bash only accepts long options before the short ones, so --rcfile comes first
+func (bashAdapter) ScriptArgs(args []string, scriptPath string) []string {
+	return append(append([]string{"--rcfile", scriptPath}, args...), "-i")
+}
+func (bashAdapter) ScriptEnv(string) []string       { return nil }
+func (bashAdapter) SelfDelete() string              { return `rm -f -- "${BASH_SOURCE[0]}"` }
+func (bashAdapter) CaptureErrors() (string, string) { return captureStderr() }
+func (bashAdapter) CommandArgs(command string) ([]string, error) {
+	return []string{"--noprofile", "--norc", "-c", command}, nil
+}
+func (bashAdapter) VersionCommand() string {
+	return "echo ${BASH_VERSINFO[0]}.${BASH_VERSINFO[1]}.${BASH_VERSINFO[2]}"
+}
+
+// zshAdapter has no rc file option, the script is written as .zshrc in its own directory and found through ZDOTDIR.
+type zshAdapter struct{}
+
+func (zshAdapter) Kind() string             { return KindZsh }
+func (zshAdapter) ProfileExtension() string { return ".Profile.zsh" }
+func (zshAdapter) ScriptName() string       { return "launch_*/.zshrc" }
+func (zshAdapter) DefaultArgs() []string    { return nil }
+func (zshAdapter) ScriptArgs(args []string, scriptPath string) []string {
+	return append(append([]string{}, args...), "-i")
+}
+func (zshAdapter) ScriptEnv(scriptPath string) []string {
+	return []string{"ZDOTDIR=" + filepath.Dir(scriptPath)}
+}
+func (zshAdapter) SelfDelete() string {
+	return `rm -f -- "${ZDOTDIR}/.zshrc" && rmdir -- "${ZDOTDIR}"; unset ZDOTDIR`
+}
+func (zshAdapter) CaptureErrors() (string, string) { return captureStderr() }
+func (zshAdapter) CommandArgs(command string) ([]string, error) {
+	return []string{"-f", "-c", command}, nil
+}
+func (zshAdapter) VersionCommand() string { return "echo $ZSH_VERSION" }
+
+// captureStderr copies stderr to the error log while the rc file runs, bash and zsh both have process substitution.
+func captureStderr() (string, string) {
+	start := `if [ -n "$` + ErrorLogEnv + `" ]; then __launcher_errors=1; exec 3>&2 2> >(tee -a -- "$` + ErrorLogEnv + `" >&3); fi; unset ` + ErrorLogEnv
+	end := `if [ -n "$__launcher_errors" ]; then exec 2>&3 3>&-; unset __launcher_errors; fi`
+	return start, end
+}
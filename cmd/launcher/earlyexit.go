@@ -0,0 +1,97 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+// ErrorLogEnv names the file the generated script copies the errors of the profiles to.
+const ErrorLogEnv = "GOPOWERSHELLLAUNCHER_ERRORS"
+
+// DefaultGracePeriod is how long a new shell is watched for exiting with an error.
+const DefaultGracePeriod = 3 * time.Second
+
+// GracePeriod is how long window and tmux launches watch the new shell. A shell that exits with a non-zero
+// code within it is reported as an EarlyExitError. Zero turns the watch off.
+var GracePeriod = DefaultGracePeriod
+
+// maxErrorLog is how much of the end of the error log is reported.
+const maxErrorLog = 4096
+
+// EarlyExitError reports a shell that exited with an error right after starting, usually a profile that failed.
+type EarlyExitError struct {
+	ExitCode int
+	After    time.Duration
+	// Stderr holds the errors the script captured while loading the profiles.
+	Stderr string
+}
+
+func (e *EarlyExitError) Error() string {
+	code := fmt.Sprintf("code %d", e.ExitCode)
+	if e.ExitCode < 0 {
+		code = "an unknown code"
+	}
+	message := fmt.Sprintf("shell exited with %s %s after starting", code, e.After.Round(10*time.Millisecond))
+	if e.Stderr != "" {
+		message += ": " + e.Stderr
+	}
+	return message
+}
+
+// sidecarPath returns a file next to the generated script, or next to its directory when the script was written
+// into one of its own, so removing the directory is not stopped by it.
+func sidecarPath(scriptPath string, suffix string) string {
+	if dir := filepath.Dir(scriptPath); dir != RuntimeDir {
+		if matched, _ := filepath.Match(sweepPattern, filepath.Base(dir)); matched {
+			return dir + suffix
+		}
+	}
+	return scriptPath + suffix
+}
+
+// ErrorLogPath returns the error log of a generated script.
+func ErrorLogPath(scriptPath string) string {
+	return sidecarPath(scriptPath, ".err")
+}
+
+// watched reports whether the launch watches the shell for an early exit.
+func (r Request) watched() bool {
+	return GracePeriod > 0 && r.Mode != ModeInline
+}
+
+// readExitFile returns the exit code a wrapper wrote to the file and removes it, -1 when there is none.
+func readExitFile(path string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return -1
+	}
+	os.Remove(path)
+	code, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return -1
+	}
+	return code
+}
+
+// checkEarlyExit returns an EarlyExitError when the shell exited with an error within the grace period,
+// with the errors the script logged. The error log is removed either way.
+func checkEarlyExit(result Result, early bool, errorLog string) error {
+	content, _ := os.ReadFile(errorLog)
+	os.Remove(errorLog)
+	if !early || !result.Waited || result.ExitCode == 0 {
+		return nil
+	}
+	stderr := strings.TrimSpace(string(content))
+	if len(stderr) > maxErrorLog {
+		stderr = "..." + stderr[len(stderr)-maxErrorLog:]
+	}
+	err := &EarlyExitError{ExitCode: result.ExitCode, After: result.Duration, Stderr: stderr}
+	l.Logger.Error("Shell exited right after starting", "ExitCode", err.ExitCode, "After", err.After, "Stderr", err.Stderr)
+	return err
+}
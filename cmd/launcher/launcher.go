@@ -1,42 +1,249 @@
 package launcher
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"syscall"
+	"strings"
+	"time"
 
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
 )
 
-var TempFiles []string
-
-func ExecutePowerShellProcess(finalProfile string, shellPath string) error {
-	l.Logger.Info("Executing PowerShell process", "ShellPath", shellPath)
-	tmpFile, tmperr := os.CreateTemp("", "encoded_command_*.ps1")
-	if tmperr != nil {
-		l.Logger.Error("Failed to create temporary file", "Error", tmperr)
-		return tmperr
-	}
-	TempFiles = append(TempFiles, tmpFile.Name())
-
-	tmpFile.WriteString(finalProfile)
-	tmpFile.Close()
-	command := fmt.Sprintf(
-		"Start-Process -FilePath \"%s\" -ArgumentList \"-NoProfile -NoExit -File %s\"",
-		shellPath, tmpFile.Name(),
-	)
-	l.Logger.Info("PowerShell command", "Command", command)
-	cmd := exec.Command("powershell", "-Command", command)
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
-
-	err := cmd.Run()
+// TerminalCommand is the terminal emulator used to open a new window for the shell on Linux and macOS.
+// An element containing {command} is replaced by the quoted shell command line, otherwise the shell
+// command is appended. When empty a platform default is used.
+var TerminalCommand []string
+
+// DefaultArgs are passed to PowerShell before the generated script when a request has no arguments.
+var DefaultArgs = []string{"-NoProfile", "-NoExit"}
+
+type Mode string
+
+const (
+	// ModeWindow opens the shell in a new window.
+	ModeWindow Mode = "window"
+	// ModeInline runs the shell in the current terminal and waits for it to exit.
+	ModeInline Mode = "inline"
+	// ModeTmux opens the shell in a new window of the current tmux session.
+	ModeTmux Mode = "tmux"
+	// ModeTmuxSplit opens the shell in a new pane of the current tmux window.
+	ModeTmuxSplit Mode = "tmux-split"
+)
+
+// Modes are the launch targets, in the order the shell view cycles through them.
+var Modes = []Mode{ModeWindow, ModeInline, ModeTmux, ModeTmuxSplit}
+
+// ParseMode returns the launch target with the name, such as tmux.
+func ParseMode(name string) (Mode, error) {
+	for _, mode := range Modes {
+		if strings.EqualFold(name, string(mode)) {
+			return mode, nil
+		}
+	}
+	names := make([]string, len(Modes))
+	for i, mode := range Modes {
+		names[i] = string(mode)
+	}
+	return "", fmt.Errorf("unknown launch target %q, use one of %s", name, strings.Join(names, ", "))
+}
+
+// Tmux reports whether the target opens the shell in tmux.
+func (m Mode) Tmux() bool {
+	return m == ModeTmux || m == ModeTmuxSplit
+}
+
+// Request describes a single shell launch.
+type Request struct {
+	// Name labels the launch, the set name when launching a set. tmux windows are named after it.
+	Name  string
+	Shell string
+	// Kind selects the shell adapter, PowerShell when empty.
+	Kind      string
+	ShellPath string
+	Profiles  []string
+	Script    string
+	Env       []string
+	Args      []string
+	WorkDir   string
+	Mode      Mode
+	// Wait blocks a window launch until the shell exits, inline launches always wait.
+	Wait bool
+	// Stdin, Stdout and Stderr are attached to an inline shell, defaulting to the launcher's own.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Adapter returns the adapter of the requested shell kind.
+func (r Request) Adapter() Adapter {
+	return AdapterFor(r.Kind)
+}
+
+// ShellArgs returns the full argument list for the shell, including the script to run.
+func (r Request) ShellArgs(scriptPath string) []string {
+	args := r.Args
+	if args == nil {
+		args = r.Adapter().DefaultArgs()
+	}
+	return r.Adapter().ScriptArgs(args, scriptPath)
+}
+
+// ShellEnv returns the environment added to the shell process for the script, with the error log of a watched launch.
+func (r Request) ShellEnv(scriptPath string) []string {
+	env := append(append([]string{}, r.Env...), r.Adapter().ScriptEnv(scriptPath)...)
+	if r.watched() {
+		env = append(env, ErrorLogEnv+"="+ErrorLogPath(scriptPath))
+	}
+	return env
+}
+
+// Result describes a started shell. ExitCode and Duration are only set once the launcher waited for the shell.
+type Result struct {
+	PID      int
+	Started  time.Time
+	Waited   bool
+	ExitCode int
+	Duration time.Duration
+}
+
+// String summarises the result for the status bar and command output.
+func (r Result) String() string {
+	pid := "unknown PID"
+	if r.PID > 0 {
+		pid = fmt.Sprintf("PID %d", r.PID)
+	}
+	summary := fmt.Sprintf("started %s (%s)", r.Started.Format("15:04:05"), pid)
+	if !r.Waited {
+		return summary
+	}
+	duration := r.Duration.Round(time.Second)
+	if r.ExitCode < 0 {
+		return fmt.Sprintf("%s, exited after %s, exit code unknown", summary, duration)
+	}
+	return fmt.Sprintf("%s, exited with code %d after %s", summary, r.ExitCode, duration)
+}
+
+// CommandLine returns the program and arguments that start the shell.
+func (r Request) CommandLine(scriptPath string) []string {
+	return append([]string{r.ShellPath}, r.ShellArgs(scriptPath)...)
+}
+
+// FormatCommandLine joins a command line for display, quoting arguments that contain spaces or quotes.
+func FormatCommandLine(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			arg = "'" + strings.ReplaceAll(arg, "'", "''") + "'"
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+type Launcher interface {
+	Launch(req Request) (Result, error)
+}
+
+// ProcessLauncher writes the request script to the runtime directory and starts the shell as a new process.
+// The script removes itself once the shell has loaded it.
+type ProcessLauncher struct{}
+
+func NewProcessLauncher() *ProcessLauncher {
+	return &ProcessLauncher{}
+}
+
+func (p *ProcessLauncher) Launch(req Request) (Result, error) {
+	l.Logger.Info("Executing shell process", "Name", req.Name, "Kind", req.Adapter().Kind(), "ShellPath", req.ShellPath, "Env", req.Env, "WorkDir", req.WorkDir, "Mode", req.Mode, "Wait", req.Wait)
+	scriptPath, err := WriteScriptAs(req.Script, req.Adapter().ScriptName())
+	if err != nil {
+		l.Logger.Error("Failed to write script", "Error", err)
+		return Result{}, err
+	}
+
+	if req.Mode == ModeInline {
+		return p.launchInline(req, scriptPath)
+	}
+	if req.Mode.Tmux() {
+		return p.launchTmux(req, scriptPath)
+	}
+
+	pidFile := sidecarPath(scriptPath, ".pid")
+	defer os.Remove(pidFile)
+	cmd, err := newWindowCommand(req.ShellPath, req.ShellArgs(scriptPath), req.WorkDir, req.ShellEnv(scriptPath), pidFile)
+	if err != nil {
+		l.Logger.Error("Failed to build launch command", "Error", err)
+		RemoveScript(scriptPath)
+		return Result{}, err
+	}
+	l.Logger.Info("Launch command", "Command", cmd.Args)
+
+	result, early, err := startWindow(cmd, pidFile, req.Wait)
 	if err != nil {
 		l.Logger.Error("Failed to start PowerShell process", "Error", err)
-		return err
+		RemoveScript(scriptPath)
+		return result, err
 	}
-	l.Logger.Debug("PowerShell process started", "PID", cmd.Process.Pid)
-	l.Logger.Info("PowerShell process started successfully")
-	return nil
+	if err := checkEarlyExit(result, early, ErrorLogPath(scriptPath)); err != nil {
+		RemoveScript(scriptPath)
+		return result, err
+	}
+	l.Logger.Info("PowerShell process started successfully", "PID", result.PID, "Waited", result.Waited, "ExitCode", result.ExitCode)
+	return result, nil
 }
+
+// launchInline runs the shell attached to the current terminal and returns once it exits.
+// The script is removed afterwards in case the shell exited before loading it.
+func (p *ProcessLauncher) launchInline(req Request, scriptPath string) (Result, error) {
+	defer RemoveScript(scriptPath)
+	cmd := exec.Command(req.ShellPath, req.ShellArgs(scriptPath)...)
+	cmd.Dir = req.WorkDir
+	cmd.Env = append(os.Environ(), req.ShellEnv(scriptPath)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if req.Stdin != nil {
+		cmd.Stdin = req.Stdin
+	}
+	if req.Stdout != nil {
+		cmd.Stdout = req.Stdout
+	}
+	if req.Stderr != nil {
+		cmd.Stderr = req.Stderr
+	}
+	l.Logger.Info("Running inline shell", "Command", cmd.Args)
+	result := Result{Started: time.Now()}
+	if err := cmd.Start(); err != nil {
+		l.Logger.Error("Failed to start inline shell", "Error", err)
+		return result, err
+	}
+	result.PID = cmd.Process.Pid
+	err := cmd.Wait()
+	result.Waited = true
+	result.ExitCode = ExitCode(err)
+	result.Duration = time.Since(result.Started)
+	if err != nil {
+		l.Logger.Error("Inline shell exited with an error", "Error", err, "ExitCode", result.ExitCode)
+		return result, err
+	}
+	l.Logger.Info("Inline shell exited", "PID", result.PID, "Duration", result.Duration)
+	return result, nil
+}
+
+// ExitCode returns the exit code of a shell from the error returned by Launch, 0 when err is nil.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	var earlyErr *EarlyExitError
+	if errors.As(err, &earlyErr) && earlyErr.ExitCode > 0 {
+		return earlyErr.ExitCode
+	}
+	return 1
+}
+
+var _ Launcher = (*ProcessLauncher)(nil)
@@ -0,0 +1,169 @@
+//go:build !windows
+
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+// pidWrapper records its PID, it runs as long as the shell, and the exit code of the shell next to the pid file.
+const pidWrapper = `echo $$ > "$0"; "$@"; code=$?; echo $code > "$0.exit"; exit $code`
+
+// pidTimeout is how long to wait for the terminal emulator to start the shell and write its PID.
+const pidTimeout = 5 * time.Second
+
+// newWindowCommand opens the shell in a new window of the configured terminal emulator.
+// The terminal emulator is not the shell, so the shell is started through a wrapper that writes its PID to pidFile.
+// env is added to the environment of the terminal emulator, which passes it on to the shell.
+func newWindowCommand(shellPath string, args []string, workDir string, env []string, pidFile string) (*exec.Cmd, error) {
+	terminal := TerminalCommand
+	if len(terminal) == 0 {
+		var err error
+		terminal, err = defaultTerminalCommand()
+		if err != nil {
+			return nil, err
+		}
+	}
+	shellArgv := append([]string{"-c", pidWrapper, pidFile, shellPath}, args...)
+	shellCommand := append([]string{"/bin/sh"}, shellArgv...)
+	if len(env) > 0 && runtime.GOOS == "darwin" {
+		// Terminal.app does not pass on the environment of osascript, so set it as part of the command
+		shellCommand = append(append([]string{"env"}, env...), shellCommand...)
+	}
+	if workDir != "" && runtime.GOOS == "darwin" {
+		// Terminal.app starts in the home directory, so change directory as part of the command
+		shellCommand = append([]string{"cd", workDir, "&&"}, shellCommand...)
+	}
+
+	var argv []string
+	substituted := false
+	for _, arg := range terminal {
+		if strings.Contains(arg, "{command}") {
+			command := quoteCommand(shellCommand)
+			if terminal[0] == "osascript" {
+				command = appleScriptEscape(command)
+			}
+			argv = append(argv, strings.ReplaceAll(arg, "{command}", command))
+			substituted = true
+			continue
+		}
+		argv = append(argv, arg)
+	}
+	if !substituted {
+		argv = append(argv, "/bin/sh")
+		argv = append(argv, shellArgv...)
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), env...)
+	// detach from the launcher so closing it leaves the terminal running
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	return cmd, nil
+}
+
+// startWindow starts the terminal emulator without waiting for it, some emulators return straight away and
+// others only exit when their window is closed. The shell PID is read from the pid file and polled until the shell
+// exits when wait is set, or for the grace period otherwise. early reports whether it exited within the grace period.
+func startWindow(cmd *exec.Cmd, pidFile string, wait bool) (Result, bool, error) {
+	result := Result{Started: time.Now()}
+	if err := cmd.Start(); err != nil {
+		return result, false, err
+	}
+	go cmd.Wait()
+
+	pid, err := readPIDFile(pidFile, pidTimeout)
+	if err != nil {
+		l.Logger.Warn("Failed to read shell PID", "pidFile", pidFile, "error", err)
+		return result, false, nil
+	}
+	result.PID = pid
+	shellStarted := time.Now()
+	if !wait && GracePeriod <= 0 {
+		return result, false, nil
+	}
+	for processAlive(pid) {
+		if !wait && time.Since(shellStarted) > GracePeriod {
+			return result, false, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	result.Waited = true
+	result.ExitCode = readExitFile(pidFile + ".exit")
+	result.Duration = time.Since(result.Started)
+	return result, time.Since(shellStarted) <= GracePeriod, nil
+}
+
+func readPIDFile(pidFile string, timeout time.Duration) (int, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		content, err := os.ReadFile(pidFile)
+		if err == nil {
+			if pid, convErr := strconv.Atoi(strings.TrimSpace(string(content))); convErr == nil && pid > 0 {
+				return pid, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("shell did not report its PID within %s", timeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// processAlive sends signal 0, which only checks that the process exists.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+func defaultTerminalCommand() ([]string, error) {
+	if runtime.GOOS == "darwin" {
+		return []string{"osascript", "-e", `tell application "Terminal" to do script "{command}"`, "-e", `tell application "Terminal" to activate`}, nil
+	}
+	if terminal := os.Getenv("TERMINAL"); terminal != "" {
+		return []string{terminal, "-e"}, nil
+	}
+	candidates := [][]string{
+		{"x-terminal-emulator", "-e"},
+		{"gnome-terminal", "--"},
+		{"konsole", "-e"},
+		{"xfce4-terminal", "-x"},
+		{"alacritty", "-e"},
+		{"kitty"},
+		{"xterm", "-e"},
+	}
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err == nil {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("no terminal emulator found, set terminal.command in the configuration")
+}
+
+// appleScriptEscape escapes a command for a double quoted AppleScript string.
+func appleScriptEscape(command string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(command)
+}
+
+// quoteCommand joins a command for a POSIX shell, leaving the && operator unquoted.
+func quoteCommand(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		if arg == "&&" {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package launcher
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewWindowCommandEnv(t *testing.T) {
+	useTerminalCommand(t, []string{"osascript", "-e", `tell application "Terminal" to do script "{command}"`})
+	env := []string{"FOO=bar baz", "ZDOTDIR=/tmp/launch"}
+	cmd, err := newWindowCommand("/bin/zsh", []string{"-i"}, "/src", env, "/tmp/launch.pid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pair := range env {
+		if !slices.Contains(cmd.Env, pair) {
+			t.Errorf("Env is missing %q", pair)
+		}
+	}
+	script := cmd.Args[2]
+	// Terminal.app starts the command without the environment of osascript
+	prefixed := strings.Contains(script, `'cd' '/src' && 'env' 'FOO=bar baz' 'ZDOTDIR=/tmp/launch' '/bin/sh'`)
+	if prefixed != (runtime.GOOS == "darwin") {
+		t.Errorf("command = %s, want the environment set in the command on darwin only", script)
+	}
+}
@@ -0,0 +1,51 @@
+package launcher
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// createNewConsole is CREATE_NEW_CONSOLE, which the syscall package does not define.
+const createNewConsole = 0x00000010
+
+// newWindowCommand starts the shell directly in a new console window, so the process is the shell itself.
+// The pid file is only needed where the shell is started through a terminal emulator.
+func newWindowCommand(shellPath string, args []string, workDir string, env []string, pidFile string) (*exec.Cmd, error) {
+	cmd := exec.Command(shellPath, args...)
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: createNewConsole | syscall.CREATE_NEW_PROCESS_GROUP}
+	return cmd, nil
+}
+
+// startWindow starts the shell and, when wait is set, waits for the console to be closed. Otherwise the shell is
+// watched for the grace period, early reports whether it exited within it.
+func startWindow(cmd *exec.Cmd, pidFile string, wait bool) (Result, bool, error) {
+	result := Result{Started: time.Now()}
+	if err := cmd.Start(); err != nil {
+		return result, false, err
+	}
+	result.PID = cmd.Process.Pid
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	// a nil channel never fires, so a waited launch only returns once the shell exits
+	var timeout <-chan time.Time
+	if !wait {
+		if GracePeriod <= 0 {
+			return result, false, nil
+		}
+		timeout = time.After(GracePeriod)
+	}
+	select {
+	case err := <-done:
+		result.Waited = true
+		result.ExitCode = ExitCode(err)
+		result.Duration = time.Since(result.Started)
+		return result, result.Duration <= GracePeriod, nil
+	case <-timeout:
+		return result, false, nil
+	}
+}
@@ -0,0 +1,111 @@
+package launcher
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Plan is what a launch would do, reported by a dry run instead of starting the shell.
+type Plan struct {
+	Name   string `json:"name,omitempty"`
+	Shell  string `json:"shell"`
+	Kind   string `json:"kind"`
+	Target Mode   `json:"target"`
+	// ShellPath and Argv are the shell binary and its full argument vector, starting with the binary.
+	ShellPath string   `json:"shellPath"`
+	Argv      []string `json:"argv"`
+	// Command is the process the launcher starts: the shell itself, a terminal emulator or tmux.
+	Command    []string `json:"command"`
+	Env        []string `json:"env"`
+	WorkDir    string   `json:"workDir"`
+	ScriptPath string   `json:"scriptPath"`
+	Script     string   `json:"script"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// NewPlan resolves a request the way Launch would, without writing the script or starting anything.
+// The script path names the script dry-run where the real launch uses a random name, so the plan of a
+// request is the same on every run. A window launch whose terminal emulator cannot be found is reported
+// as a warning without a command.
+func NewPlan(req Request) (Plan, error) {
+	scriptPath, err := plannedScriptPath(req.Adapter().ScriptName())
+	if err != nil {
+		return Plan{}, err
+	}
+	plan := Plan{
+		Name:       req.Name,
+		Shell:      req.Shell,
+		Kind:       req.Adapter().Kind(),
+		Target:     req.Mode,
+		ShellPath:  req.ShellPath,
+		Argv:       req.CommandLine(scriptPath),
+		Env:        req.ShellEnv(scriptPath),
+		WorkDir:    req.WorkDir,
+		ScriptPath: scriptPath,
+		Script:     req.Script,
+	}
+	if plan.Target == "" {
+		plan.Target = ModeWindow
+	}
+	switch {
+	case plan.Target == ModeInline:
+		plan.Command = plan.Argv
+	case plan.Target.Tmux():
+		plan.Command = TmuxCommand(req, scriptPath)
+	default:
+		cmd, err := newWindowCommand(req.ShellPath, req.ShellArgs(scriptPath), req.WorkDir, plan.Env, sidecarPath(scriptPath, ".pid"))
+		if err != nil {
+			plan.Warnings = append(plan.Warnings, err.Error())
+			break
+		}
+		plan.Command = cmd.Args
+	}
+	return plan, nil
+}
+
+// String describes the plan for people, the script follows the summary.
+func (p Plan) String() string {
+	var b strings.Builder
+	if p.Name != "" {
+		fmt.Fprintf(&b, "Set: %s\n", p.Name)
+	}
+	fmt.Fprintf(&b, "Shell: %s (%s)\n", p.Shell, p.Kind)
+	fmt.Fprintf(&b, "Target: %s\n", p.Target)
+	fmt.Fprintf(&b, "Binary: %s\n", p.ShellPath)
+	fmt.Fprintf(&b, "Arguments: %s\n", FormatCommandLine(p.Argv))
+	if len(p.Command) == 0 {
+		b.WriteString("Command: none\n")
+	} else {
+		fmt.Fprintf(&b, "Command: %s\n", FormatCommandLine(p.Command))
+	}
+	if len(p.Env) == 0 {
+		b.WriteString("Environment: none\n")
+	}
+	for _, pair := range p.Env {
+		fmt.Fprintf(&b, "Environment: %s\n", pair)
+	}
+	workDir := p.WorkDir
+	if workDir == "" {
+		workDir = "default"
+	}
+	fmt.Fprintf(&b, "Working directory: %s\n", workDir)
+	for _, warning := range p.Warnings {
+		fmt.Fprintf(&b, "Warning: %s\n", warning)
+	}
+	fmt.Fprintf(&b, "Script: %s\n", p.ScriptPath)
+	b.WriteString("-----\n")
+	b.WriteString(p.Script)
+	return b.String()
+}
+
+// plannedScriptPath returns the path of the pattern in the runtime directory with dry-run for the random part,
+// without creating anything.
+func plannedScriptPath(pattern string) (string, error) {
+	dir, err := runtimeDirPath()
+	if err != nil {
+		return "", err
+	}
+	name := strings.Replace(pattern, "*", "dry-run", 1)
+	return filepath.Join(dir, filepath.FromSlash(name)), nil
+}
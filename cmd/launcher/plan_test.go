@@ -0,0 +1,191 @@
+package launcher
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// usePlanRuntimeDir points the script directory at a temporary directory for the test.
+func usePlanRuntimeDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	previous := RuntimeDir
+	RuntimeDir = dir
+	t.Cleanup(func() { RuntimeDir = previous })
+	return dir
+}
+
+func useTerminalCommand(t *testing.T, command []string) {
+	t.Helper()
+	previous := TerminalCommand
+	TerminalCommand = command
+	t.Cleanup(func() { TerminalCommand = previous })
+}
+
+func TestNewPlanInline(t *testing.T) {
+	dir := usePlanRuntimeDir(t)
+	req := Request{Name: "My Dev", Shell: "bash", Kind: KindBash, ShellPath: "/bin/bash", Env: []string{"FOO=bar"}, WorkDir: "/src", Mode: ModeInline, Script: "echo hi\n"}
+	plan, err := NewPlan(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantScript := filepath.Join(dir, "launch_dry-run.sh")
+	if plan.ScriptPath != wantScript {
+		t.Errorf("ScriptPath = %q, want %q", plan.ScriptPath, wantScript)
+	}
+	if len(plan.Argv) == 0 || plan.Argv[0] != "/bin/bash" || !slices.Contains(plan.Argv, wantScript) {
+		t.Errorf("Argv = %v, want the shell loading %s", plan.Argv, wantScript)
+	}
+	if !reflect.DeepEqual(plan.Command, plan.Argv) {
+		t.Errorf("Command = %v, want the shell itself %v", plan.Command, plan.Argv)
+	}
+	if plan.Target != ModeInline || plan.Kind != KindBash || plan.Name != "My Dev" || plan.WorkDir != "/src" || plan.Script != "echo hi\n" {
+		t.Errorf("plan = %+v", plan)
+	}
+	if !slices.Contains(plan.Env, "FOO=bar") {
+		t.Errorf("Env = %v, want FOO=bar", plan.Env)
+	}
+	if len(plan.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want none", plan.Warnings)
+	}
+}
+
+func TestNewPlanIsDeterministic(t *testing.T) {
+	usePlanRuntimeDir(t)
+	useTerminalCommand(t, []string{"xterm", "-e"})
+	for _, mode := range []Mode{ModeInline, ModeWindow, ModeTmux} {
+		req := Request{Name: "My Dev", Shell: "bash", Kind: KindBash, ShellPath: "/bin/bash", Env: []string{"FOO=bar"}, Mode: mode, Script: "echo hi\n"}
+		first, err := NewPlan(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := NewPlan(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("%s plans differ:\n%+v\n%+v", mode, first, second)
+		}
+	}
+}
+
+func TestNewPlanDefaultsToWindow(t *testing.T) {
+	usePlanRuntimeDir(t)
+	useTerminalCommand(t, []string{"xterm", "-e"})
+	plan, err := NewPlan(Request{Shell: "bash", Kind: KindBash, ShellPath: "/bin/bash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Target != ModeWindow {
+		t.Errorf("Target = %q, want window", plan.Target)
+	}
+}
+
+func TestNewPlanWindowZshPidFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows starts the shell directly, without a pid file")
+	}
+	dir := usePlanRuntimeDir(t)
+	useTerminalCommand(t, []string{"xterm", "-e"})
+	plan, err := NewPlan(Request{Shell: "zsh", Kind: KindZsh, ShellPath: "/bin/zsh", Mode: ModeWindow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.ScriptPath != filepath.Join(dir, "launch_dry-run", ".zshrc") {
+		t.Errorf("ScriptPath = %q", plan.ScriptPath)
+	}
+	// the pid file sits next to the script directory, as on a real launch
+	pidFile := filepath.Join(dir, "launch_dry-run.pid")
+	if plan.Command[0] != "xterm" || !slices.Contains(plan.Command, pidFile) {
+		t.Errorf("Command = %v, want xterm writing %s", plan.Command, pidFile)
+	}
+}
+
+func TestNewPlanWithoutTerminalEmulator(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only linux looks the terminal emulator up on the path")
+	}
+	usePlanRuntimeDir(t)
+	useTerminalCommand(t, nil)
+	t.Setenv("TERMINAL", "")
+	t.Setenv("PATH", t.TempDir())
+	plan, err := NewPlan(Request{Shell: "bash", Kind: KindBash, ShellPath: "/bin/bash", Env: []string{"FOO=bar"}, WorkDir: "/src", Script: "echo hi\n"})
+	if err != nil {
+		t.Fatalf("NewPlan: %v", err)
+	}
+	if len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0], "no terminal emulator found") {
+		t.Errorf("Warnings = %v, want the missing terminal emulator", plan.Warnings)
+	}
+	if plan.Command != nil {
+		t.Errorf("Command = %v, want none", plan.Command)
+	}
+	if len(plan.Argv) == 0 || plan.Script == "" || plan.WorkDir != "/src" || !slices.Contains(plan.Env, "FOO=bar") {
+		t.Errorf("plan is missing the shell details: %+v", plan)
+	}
+	text := plan.String()
+	for _, want := range []string{"Command: none", "Warning: no terminal emulator found", "Arguments: /bin/bash", "echo hi"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("String() is missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestNewPlanTmux(t *testing.T) {
+	usePlanRuntimeDir(t)
+	plan, err := NewPlan(Request{Name: "My Dev", Shell: "bash", Kind: KindBash, ShellPath: "/bin/bash", WorkDir: "/src", Mode: ModeTmuxSplit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Command) < 2 || plan.Command[0] != "tmux" || plan.Command[1] != "split-window" {
+		t.Errorf("Command = %v, want tmux split-window", plan.Command)
+	}
+}
+
+func TestPlanJSONShape(t *testing.T) {
+	usePlanRuntimeDir(t)
+	plan, err := NewPlan(Request{Name: "My Dev", Shell: "bash", Kind: KindBash, ShellPath: "/bin/bash", Env: []string{"FOO=bar"}, Mode: ModeInline, Script: "echo <hi>\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := func(plan Plan) []string {
+		content, err := json.Marshal(plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(content, &fields); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	want := []string{"argv", "command", "env", "kind", "name", "script", "scriptPath", "shell", "shellPath", "target", "workDir"}
+	if got := keys(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+	plan.Name = ""
+	plan.Warnings = []string{"careful"}
+	want = []string{"argv", "command", "env", "kind", "script", "scriptPath", "shell", "shellPath", "target", "warnings", "workDir"}
+	if got := keys(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("keys without a name and with warnings = %v, want %v", got, want)
+	}
+
+	var decoded Plan
+	content, _ := json.Marshal(plan)
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, plan) {
+		t.Errorf("decoded plan = %+v, want %+v", decoded, plan)
+	}
+}
@@ -0,0 +1,30 @@
+package launcher
+
+import (
+	"sync"
+	"time"
+)
+
+// RecordingLauncher records requests instead of starting shells, for tests.
+type RecordingLauncher struct {
+	mu       sync.Mutex
+	requests []Request
+	// Err is returned from every Launch call when set.
+	Err error
+}
+
+func (r *RecordingLauncher) Launch(req Request) (Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	return Result{Started: time.Now()}, r.Err
+}
+
+// Requests returns the recorded requests in launch order.
+func (r *RecordingLauncher) Requests() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Request{}, r.requests...)
+}
+
+var _ Launcher = (*RecordingLauncher)(nil)
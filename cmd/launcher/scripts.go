@@ -0,0 +1,141 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+// sweepPattern matches the generated scripts and the files and directories written alongside them.
+// The sweeper only removes files written by the launcher.
+const sweepPattern = "launch_*"
+
+// RuntimeDir holds the generated scripts. When empty a per-user directory in the user cache directory is used.
+var RuntimeDir string
+
+// DefaultScriptMaxAge is how old a generated script has to be before the sweeper removes it.
+const DefaultScriptMaxAge = 24 * time.Hour
+
+// runtimeDirPath returns the script directory without creating it.
+func runtimeDirPath() (string, error) {
+	if RuntimeDir != "" {
+		return RuntimeDir, nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting user cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "GoPowerShellLauncher", "scripts"), nil
+}
+
+// EnsureRuntimeDir creates the script directory, readable only by the current user.
+func EnsureRuntimeDir() (string, error) {
+	dir, err := runtimeDirPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("error creating runtime directory: %w", err)
+	}
+	// MkdirAll leaves an existing directory as it is, tighten it in case it was created by something else
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return "", fmt.Errorf("error setting runtime directory permissions: %w", err)
+	}
+	return dir, nil
+}
+
+// WriteScript writes a generated PowerShell script to the runtime directory and returns its path.
+func WriteScript(script string) (string, error) {
+	return WriteScriptAs(script, AdapterFor(KindPowerShell).ScriptName())
+}
+
+// WriteScriptAs writes a generated script named after the pattern, the * is replaced by a random string.
+// A pattern such as launch_*/.zshrc writes the script with a fixed name into a new directory.
+func WriteScriptAs(script string, pattern string) (string, error) {
+	dir, err := EnsureRuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	var file *os.File
+	if dirPattern, name, ok := strings.Cut(pattern, "/"); ok {
+		scriptDir, err := os.MkdirTemp(dir, dirPattern)
+		if err != nil {
+			return "", fmt.Errorf("error creating script directory: %w", err)
+		}
+		file, err = os.OpenFile(filepath.Join(scriptDir, name), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			os.Remove(scriptDir)
+			return "", fmt.Errorf("error creating script file: %w", err)
+		}
+	} else {
+		file, err = os.CreateTemp(dir, pattern)
+		if err != nil {
+			return "", fmt.Errorf("error creating script file: %w", err)
+		}
+	}
+	defer file.Close()
+	if err := file.Chmod(0o600); err != nil {
+		l.Logger.Warn("Failed to set script permissions", "path", file.Name(), "error", err)
+	}
+	if _, err := file.WriteString(script); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error writing script file: %w", err)
+	}
+	l.Logger.Debug("Script written", "path", file.Name())
+	return file.Name(), nil
+}
+
+// RemoveScript removes a generated script, with its directory when it was written into one of its own.
+func RemoveScript(path string) {
+	os.Remove(path)
+	if dir := filepath.Dir(path); dir != RuntimeDir {
+		if matched, _ := filepath.Match(sweepPattern, filepath.Base(dir)); matched {
+			os.Remove(dir)
+		}
+	}
+}
+
+// SweepScripts removes generated scripts older than maxAge, left behind when a shell failed to start or the launcher crashed.
+// It returns the number of files removed.
+func SweepScripts(maxAge time.Duration) (int, error) {
+	if maxAge <= 0 {
+		maxAge = DefaultScriptMaxAge
+	}
+	dir, err := EnsureRuntimeDir()
+	if err != nil {
+		return 0, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("error reading runtime directory: %w", err)
+	}
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, entry := range entries {
+		if matched, _ := filepath.Match(sweepPattern, entry.Name()); !matched {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		remove := os.Remove
+		if entry.IsDir() {
+			remove = os.RemoveAll
+		}
+		if err := remove(path); err != nil {
+			l.Logger.Warn("Failed to remove stale script", "path", path, "error", err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		l.Logger.Info("Removed stale scripts", "dir", dir, "count", removed)
+	}
+	return removed, nil
+}
@@ -0,0 +1,139 @@
+package launcher
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+// tmuxExitWrapper records the exit code of the shell, the pane closes with it.
+const tmuxExitWrapper = `"$@"; code=$?; echo $code > "$0"; exit $code`
+
+// TmuxSplit is the direction of tmux-split launches: vertical puts the new pane below, horizontal beside.
+var TmuxSplit = "vertical"
+
+// InTmux reports whether the launcher runs inside a tmux session.
+func InTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// TmuxWindowName turns a set or shell name into a tmux window name, runs of other characters than letters,
+// digits, - and _ become a single -.
+func TmuxWindowName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.TrimSpace(name) {
+		if r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// TmuxCommand returns the tmux command that opens the shell of a tmux or tmux-split request.
+// The environment is passed with -e and the shell runs through a wrapper recording its exit code,
+// not through the default shell of tmux.
+func TmuxCommand(req Request, scriptPath string) []string {
+	argv := []string{"tmux"}
+	if req.Mode == ModeTmuxSplit {
+		argv = append(argv, "split-window")
+		if TmuxSplit == "horizontal" {
+			argv = append(argv, "-h")
+		}
+	} else {
+		argv = append(argv, "new-window")
+		name := req.Name
+		if name == "" {
+			name = req.Shell
+		}
+		if name = TmuxWindowName(name); name != "" {
+			argv = append(argv, "-n", name)
+		}
+	}
+	// print the pane so the launcher can report the PID and wait for it
+	argv = append(argv, "-P", "-F", "#{pane_id} #{pane_pid}")
+	workDir := req.WorkDir
+	if workDir == "" {
+		// tmux would start in the directory of the session, not the one the launcher runs in
+		workDir, _ = os.Getwd()
+	}
+	if workDir != "" {
+		argv = append(argv, "-c", workDir)
+	}
+	for _, pair := range req.ShellEnv(scriptPath) {
+		argv = append(argv, "-e", pair)
+	}
+	argv = append(argv, "--", "/bin/sh", "-c", tmuxExitWrapper, sidecarPath(scriptPath, ".exit"))
+	return append(argv, req.CommandLine(scriptPath)...)
+}
+
+// launchTmux opens the shell in a tmux window or pane. The pane is polled until it closes when wait is set,
+// or for the grace period otherwise.
+func (p *ProcessLauncher) launchTmux(req Request, scriptPath string) (Result, error) {
+	argv := TmuxCommand(req, scriptPath)
+	l.Logger.Info("Tmux command", "Command", argv)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	result := Result{Started: time.Now()}
+	output, err := cmd.Output()
+	if err != nil {
+		RemoveScript(scriptPath)
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			err = fmt.Errorf("%s: %s", err, message)
+		}
+		l.Logger.Error("Failed to open tmux pane", "Error", err)
+		return result, fmt.Errorf("tmux %s failed: %w", argv[1], err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) < 2 {
+		l.Logger.Warn("Unexpected tmux output", "output", string(output))
+		return result, nil
+	}
+	paneID := fields[0]
+	result.PID, _ = strconv.Atoi(fields[1])
+	l.Logger.Info("Tmux pane opened", "pane", paneID, "PID", result.PID)
+	if !req.Wait && GracePeriod <= 0 {
+		return result, nil
+	}
+	for tmuxPaneExists(paneID) {
+		if !req.Wait && time.Since(result.Started) > GracePeriod {
+			os.Remove(ErrorLogPath(scriptPath))
+			return result, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	result.Waited = true
+	result.ExitCode = readExitFile(sidecarPath(scriptPath, ".exit"))
+	result.Duration = time.Since(result.Started)
+	if err := checkEarlyExit(result, result.Duration <= GracePeriod, ErrorLogPath(scriptPath)); err != nil {
+		RemoveScript(scriptPath)
+		return result, err
+	}
+	return result, nil
+}
+
+func tmuxPaneExists(paneID string) bool {
+	output, err := exec.Command("tmux", "list-panes", "-a", "-F", "#{pane_id}").Output()
+	if err != nil {
+		return false
+	}
+	for _, id := range strings.Fields(string(output)) {
+		if id == paneID {
+			return true
+		}
+	}
+	return false
+}
@@ -11,7 +11,8 @@ import (
 	"github.com/charmbracelet/log"
 )
 
-var Logger *log.Logger
+// Logger discards everything until InitLogger runs, so code that logs works in tests and before startup.
+var Logger = log.New(io.Discard)
 var logFile *os.File
 
 const maxLogSize = 10 * 1024 * 1024 // 10 MB
@@ -4,16 +4,23 @@ Copyright © 2024 Nigel Tatschner <ntatschner@gmail.com>
 package cmd
 
 import (
-	"os"
-
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/mainview"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
 
+var processLauncher = launcher.NewProcessLauncher()
+
+// deps are shared by the CLI commands and handed to the UI views
+var deps = view.Dependencies{
+	Launcher: utils.NewHistoryLauncher(processLauncher),
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "GoPowerShellLauncher",
@@ -25,20 +32,25 @@ var rootCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		l.Logger.Info("Launching PowerShell Launcher UI")
 		w, h := utils.GetWindowSize()
-		tprogram := tea.NewProgram(mainview.NewMainModel(tea.WindowSizeMsg{Width: w, Height: h}), tea.WithAltScreen(), tea.WithFPS(120))
+		tprogram := tea.NewProgram(mainview.NewMainModel(tea.WindowSizeMsg{Width: w, Height: h}, deps), tea.WithAltScreen(), tea.WithFPS(120))
 		if _, err := tprogram.Run(); err != nil {
 			l.Logger.Error("Error starting the program", "Error", err)
 		}
 	},
 }
 
+// exitCode is set by commands that finish with the exit code of an inline shell.
+var exitCode int
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() {
+// It returns the exit code for the process.
+func Execute() int {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		return 1
 	}
+	return exitCode
 }
 
 func init() {
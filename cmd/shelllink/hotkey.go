@@ -0,0 +1,100 @@
+package shelllink
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HotKey is the keyboard shortcut of a link: the virtual key code in the low byte, the modifiers in the high byte.
+type HotKey uint16
+
+const (
+	hotKeyShift   = 0x01
+	hotKeyControl = 0x02
+	hotKeyAlt     = 0x04
+)
+
+var namedKeys = map[string]uint16{
+	"numlock":    0x90,
+	"scrolllock": 0x91,
+}
+
+// ParseHotKey parses a hot key such as Ctrl+Alt+P or Ctrl+Shift+F5. Windows only accepts hot keys with
+// Ctrl or Alt, so one of them is required. An empty string is no hot key.
+func ParseHotKey(s string) (HotKey, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	parts := strings.Split(s, "+")
+	var modifiers, key uint16
+	for i, part := range parts {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if i < len(parts)-1 {
+			switch name {
+			case "ctrl", "control":
+				modifiers |= hotKeyControl
+			case "alt":
+				modifiers |= hotKeyAlt
+			case "shift":
+				modifiers |= hotKeyShift
+			default:
+				return 0, fmt.Errorf("invalid hot key modifier %q in %q, use Ctrl, Alt or Shift", part, s)
+			}
+			continue
+		}
+		switch {
+		case len(name) == 1 && name[0] >= 'a' && name[0] <= 'z':
+			key = uint16(name[0]-'a') + 'A'
+		case len(name) == 1 && name[0] >= '0' && name[0] <= '9':
+			key = uint16(name[0])
+		case len(name) > 1 && name[0] == 'f':
+			n, err := strconv.Atoi(name[1:])
+			if err != nil || n < 1 || n > 24 {
+				return 0, fmt.Errorf("invalid hot key %q", s)
+			}
+			key = 0x70 + uint16(n-1)
+		default:
+			code, ok := namedKeys[name]
+			if !ok {
+				return 0, fmt.Errorf("invalid hot key %q, the key has to be a letter, a digit, F1 to F24, NumLock or ScrollLock", s)
+			}
+			key = code
+		}
+	}
+	if modifiers&(hotKeyControl|hotKeyAlt) == 0 {
+		return 0, fmt.Errorf("hot key %q needs Ctrl or Alt", s)
+	}
+	return HotKey(modifiers<<8 | key), nil
+}
+
+// String formats the hot key the way ParseHotKey reads it.
+func (h HotKey) String() string {
+	if h == 0 {
+		return ""
+	}
+	var parts []string
+	modifiers, key := uint16(h)>>8, uint16(h)&0xFF
+	if modifiers&hotKeyControl != 0 {
+		parts = append(parts, "Ctrl")
+	}
+	if modifiers&hotKeyAlt != 0 {
+		parts = append(parts, "Alt")
+	}
+	if modifiers&hotKeyShift != 0 {
+		parts = append(parts, "Shift")
+	}
+	switch {
+	case key >= 'A' && key <= 'Z', key >= '0' && key <= '9':
+		parts = append(parts, string(rune(key)))
+	case key >= 0x70 && key <= 0x87:
+		parts = append(parts, "F"+strconv.Itoa(int(key-0x70+1)))
+	case key == 0x90:
+		parts = append(parts, "NumLock")
+	case key == 0x91:
+		parts = append(parts, "ScrollLock")
+	default:
+		parts = append(parts, fmt.Sprintf("0x%02X", key))
+	}
+	return strings.Join(parts, "+")
+}
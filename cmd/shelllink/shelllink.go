@@ -0,0 +1,381 @@
+// Package shelllink reads and writes Windows shortcuts, the Shell Link (.lnk) binary format described in MS-SHLLINK.
+// It is plain Go, so shortcuts can be written on any operating system.
+package shelllink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+// headerSize is the size of the ShellLinkHeader, the only supported value.
+const headerSize = 0x4C
+
+// linkCLSID is the class identifier every shell link header carries, 00021401-0000-0000-C000-000000000046.
+var linkCLSID = [16]byte{0x01, 0x14, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}
+
+// LinkFlags of the header, telling which optional structures follow it.
+const (
+	hasLinkTargetIDList = 1 << 0
+	hasLinkInfo         = 1 << 1
+	hasName             = 1 << 2
+	hasRelativePath     = 1 << 3
+	hasWorkingDir       = 1 << 4
+	hasArguments        = 1 << 5
+	hasIconLocation     = 1 << 6
+	isUnicode           = 1 << 7
+	hasExpString        = 1 << 9
+)
+
+// LinkInfoFlags
+const (
+	volumeIDAndLocalBasePath               = 1 << 0
+	commonNetworkRelativeLinkAndPathSuffix = 1 << 1
+)
+
+const (
+	// environmentVariableBlock is the signature of the extra data block holding the target path.
+	environmentVariableBlock = 0xA0000001
+	environmentVariableSize  = 0x314
+	// maxPath is the size in characters of the paths of the environment variable block, with the terminator.
+	maxPath = 260
+	// driveFixed is the drive type written into the volume ID.
+	driveFixed = 3
+	// fileAttrArchive is the attribute of the target recorded in the header.
+	fileAttrArchive = 0x20
+)
+
+// ShowCommand is how the window of the target is opened.
+type ShowCommand uint32
+
+const (
+	ShowNormal    ShowCommand = 1
+	ShowMaximized ShowCommand = 3
+	ShowMinimized ShowCommand = 7
+)
+
+// Link is the part of a shortcut the launcher writes and reads.
+type Link struct {
+	Target       string
+	Arguments    string
+	WorkDir      string
+	Description  string
+	IconLocation string
+	IconIndex    int32
+	HotKey       HotKey
+	ShowCommand  ShowCommand
+}
+
+type header struct {
+	HeaderSize     uint32
+	LinkCLSID      [16]byte
+	LinkFlags      uint32
+	FileAttributes uint32
+	CreationTime   uint64
+	AccessTime     uint64
+	WriteTime      uint64
+	FileSize       uint32
+	IconIndex      int32
+	ShowCommand    uint32
+	HotKey         uint16
+	Reserved1      uint16
+	Reserved2      uint32
+	Reserved3      uint32
+}
+
+// WriteFile writes the link to path, replacing an existing file.
+func WriteFile(path string, link Link) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, link); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Write encodes the link. The target is stored in the link info, so Windows finds it without an ID list,
+// and in an environment variable block when it fits, which also covers network paths.
+func Write(w io.Writer, link Link) error {
+	if link.Target == "" {
+		return errors.New("shell link target cannot be empty")
+	}
+	h := header{
+		HeaderSize:     headerSize,
+		LinkCLSID:      linkCLSID,
+		LinkFlags:      isUnicode,
+		FileAttributes: fileAttrArchive,
+		IconIndex:      link.IconIndex,
+		ShowCommand:    uint32(link.ShowCommand),
+		HotKey:         uint16(link.HotKey),
+	}
+	if h.ShowCommand == 0 {
+		h.ShowCommand = uint32(ShowNormal)
+	}
+	unc := strings.HasPrefix(link.Target, `\\`)
+	if !unc {
+		h.LinkFlags |= hasLinkInfo
+	}
+	strs := []struct {
+		flag  uint32
+		value string
+	}{
+		{hasName, link.Description},
+		{hasWorkingDir, link.WorkDir},
+		{hasArguments, link.Arguments},
+		{hasIconLocation, link.IconLocation},
+	}
+	for _, s := range strs {
+		if s.value != "" {
+			h.LinkFlags |= s.flag
+		}
+	}
+	if len(utf16.Encode([]rune(link.Target))) < maxPath {
+		h.LinkFlags |= hasExpString
+	} else if unc {
+		return fmt.Errorf("network target is longer than %d characters: %s", maxPath-1, link.Target)
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, h)
+	if h.LinkFlags&hasLinkInfo != 0 {
+		buf.Write(linkInfo(link.Target))
+	}
+	for _, s := range strs {
+		if h.LinkFlags&s.flag == 0 {
+			continue
+		}
+		chars := utf16.Encode([]rune(s.value))
+		if len(chars) > 0xFFFF {
+			return fmt.Errorf("shell link string is longer than %d characters", 0xFFFF)
+		}
+		binary.Write(&buf, binary.LittleEndian, uint16(len(chars)))
+		binary.Write(&buf, binary.LittleEndian, chars)
+	}
+	if h.LinkFlags&hasExpString != 0 {
+		binary.Write(&buf, binary.LittleEndian, uint32(environmentVariableSize))
+		binary.Write(&buf, binary.LittleEndian, uint32(environmentVariableBlock))
+		ansi := make([]byte, maxPath)
+		copy(ansi, ansiString(link.Target))
+		buf.Write(ansi)
+		wide := make([]uint16, maxPath)
+		copy(wide, utf16.Encode([]rune(link.Target)))
+		binary.Write(&buf, binary.LittleEndian, wide)
+	}
+	// terminal block
+	binary.Write(&buf, binary.LittleEndian, uint32(0))
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// linkInfo returns a LinkInfo structure locating the target on a fixed local volume.
+func linkInfo(target string) []byte {
+	const infoHeaderSize = 0x24
+	volumeID := []byte{0x11, 0, 0, 0, driveFixed, 0, 0, 0, 0, 0, 0, 0, 0x10, 0, 0, 0, 0}
+	basePath := append(ansiString(target), 0)
+	var wide bytes.Buffer
+	binary.Write(&wide, binary.LittleEndian, append(utf16.Encode([]rune(target)), 0))
+
+	volumeIDOffset := uint32(infoHeaderSize)
+	basePathOffset := volumeIDOffset + uint32(len(volumeID))
+	suffixOffset := basePathOffset + uint32(len(basePath))
+	basePathUnicodeOffset := suffixOffset + 1
+	suffixUnicodeOffset := basePathUnicodeOffset + uint32(wide.Len())
+	size := suffixUnicodeOffset + 2
+
+	var buf bytes.Buffer
+	for _, v := range []uint32{size, infoHeaderSize, volumeIDAndLocalBasePath, volumeIDOffset, basePathOffset, 0, suffixOffset, basePathUnicodeOffset, suffixUnicodeOffset} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.Write(volumeID)
+	buf.Write(basePath)
+	// empty common path suffix, ANSI then Unicode
+	buf.WriteByte(0)
+	buf.Write(wide.Bytes())
+	buf.Write([]byte{0, 0})
+	return buf.Bytes()
+}
+
+// ansiString stands in for the system code page: characters outside ASCII become ?, the Unicode copies
+// written next to it keep them.
+func ansiString(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0x7F {
+			r = '?'
+		}
+		out = append(out, byte(r))
+	}
+	return out
+}
+
+// ReadFile reads the link at path.
+func ReadFile(path string) (Link, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Link{}, err
+	}
+	defer f.Close()
+	link, err := Read(f)
+	if err != nil {
+		return link, fmt.Errorf("error reading shortcut %s: %w", path, err)
+	}
+	return link, nil
+}
+
+// Read decodes a link. The target is taken from the environment variable block, the link info or the
+// relative path, in that order. Links that only locate their target through the ID list have no target.
+func Read(reader io.Reader) (Link, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return Link{}, err
+	}
+	r := bytes.NewReader(data)
+	var h header
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return Link{}, fmt.Errorf("invalid shell link header: %w", err)
+	}
+	if h.HeaderSize != headerSize || h.LinkCLSID != linkCLSID {
+		return Link{}, errors.New("not a shell link")
+	}
+	link := Link{IconIndex: h.IconIndex, HotKey: HotKey(h.HotKey), ShowCommand: ShowCommand(h.ShowCommand)}
+	if h.LinkFlags&hasLinkTargetIDList != 0 {
+		var size uint16
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return link, fmt.Errorf("invalid ID list: %w", err)
+		}
+		if _, err := r.Seek(int64(size), io.SeekCurrent); err != nil {
+			return link, err
+		}
+	}
+	if h.LinkFlags&hasLinkInfo != 0 {
+		start := len(data) - r.Len()
+		var size uint32
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil || size < 4 || int64(size)-4 > int64(r.Len()) {
+			return link, errors.New("invalid link info")
+		}
+		link.Target = readLinkInfoPath(data[start : start+int(size)])
+		r.Seek(int64(size)-4, io.SeekCurrent)
+	}
+	var relativePath string
+	for _, s := range []struct {
+		flag  uint32
+		value *string
+	}{
+		{hasName, &link.Description},
+		{hasRelativePath, &relativePath},
+		{hasWorkingDir, &link.WorkDir},
+		{hasArguments, &link.Arguments},
+		{hasIconLocation, &link.IconLocation},
+	} {
+		if h.LinkFlags&s.flag == 0 {
+			continue
+		}
+		var count uint16
+		if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+			return link, fmt.Errorf("invalid string data: %w", err)
+		}
+		if h.LinkFlags&isUnicode == 0 {
+			value := make([]byte, count)
+			if _, err := io.ReadFull(r, value); err != nil {
+				return link, fmt.Errorf("invalid string data: %w", err)
+			}
+			*s.value = string(value)
+			continue
+		}
+		value := make([]uint16, count)
+		if err := binary.Read(r, binary.LittleEndian, value); err != nil {
+			return link, fmt.Errorf("invalid string data: %w", err)
+		}
+		*s.value = string(utf16.Decode(value))
+	}
+	for {
+		var size, signature uint32
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil || size < 8 {
+			break
+		}
+		if err := binary.Read(r, binary.LittleEndian, &signature); err != nil {
+			break
+		}
+		// the size comes from the file, a corrupt one must not allocate more than is left
+		if int64(size)-8 > int64(r.Len()) {
+			return link, fmt.Errorf("invalid extra data: block of %d bytes with %d left", size, r.Len())
+		}
+		block := make([]byte, size-8)
+		if _, err := io.ReadFull(r, block); err != nil {
+			return link, fmt.Errorf("invalid extra data: %w", err)
+		}
+		if signature == environmentVariableBlock && len(block) >= maxPath*3 {
+			if target := utf16String(block[maxPath:]); target != "" {
+				link.Target = target
+			} else if target := cString(block[:maxPath]); target != "" {
+				link.Target = target
+			}
+		}
+	}
+	if link.Target == "" {
+		link.Target = relativePath
+	}
+	return link, nil
+}
+
+// readLinkInfoPath returns the local path of a LinkInfo structure, or the network share path with its suffix.
+func readLinkInfoPath(info []byte) string {
+	field := func(offset int) int {
+		if offset+4 > len(info) {
+			return 0
+		}
+		return int(binary.LittleEndian.Uint32(info[offset:]))
+	}
+	at := func(offset int) []byte {
+		if offset <= 0 || offset >= len(info) {
+			return nil
+		}
+		return info[offset:]
+	}
+	infoHeaderSize, flags := field(4), field(8)
+	suffix := cString(at(field(24)))
+	if infoHeaderSize >= 0x24 {
+		if flags&volumeIDAndLocalBasePath != 0 {
+			if base := utf16String(at(field(28))); base != "" {
+				return base + utf16String(at(field(32)))
+			}
+		}
+	}
+	if flags&volumeIDAndLocalBasePath != 0 {
+		return cString(at(field(16))) + suffix
+	}
+	if flags&commonNetworkRelativeLinkAndPathSuffix != 0 {
+		network := at(field(20))
+		if len(network) >= 20 {
+			share := cString(at(int(binary.LittleEndian.Uint32(network[8:])) + field(20)))
+			if share != "" && suffix != "" {
+				return share + `\` + suffix
+			}
+			return share
+		}
+	}
+	return ""
+}
+
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+func utf16String(b []byte) string {
+	var chars []uint16
+	for i := 0; i+1 < len(b); i += 2 {
+		c := binary.LittleEndian.Uint16(b[i:])
+		if c == 0 {
+			break
+		}
+		chars = append(chars, c)
+	}
+	return string(utf16.Decode(chars))
+}
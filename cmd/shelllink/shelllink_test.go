@@ -0,0 +1,166 @@
+package shelllink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	long := `C:\` + strings.Repeat(`deep\`, 60) + "launcher.exe"
+	if len(long) < 260 {
+		t.Fatalf("long target has only %d characters", len(long))
+	}
+	tests := []struct {
+		name string
+		link Link
+	}{
+		{"all fields", Link{
+			Target:       `C:\Tools\GoPowerShellLauncher.exe`,
+			Arguments:    `launch "O'Brien Work"`,
+			WorkDir:      `C:\Users\dev\source`,
+			Description:  "Shortcut to launch GoPowerShellLauncher",
+			IconLocation: `C:\Program Files\PowerShell\7\pwsh.exe`,
+			IconIndex:    2,
+			HotKey:       HotKey(0x0600 | 'P'),
+			ShowCommand:  ShowMaximized,
+		}},
+		{"target only", Link{Target: `C:\launcher.exe`, ShowCommand: ShowNormal}},
+		{"unc target", Link{Target: `\\server\share\tools\launcher.exe`, Arguments: "launch Work", ShowCommand: ShowNormal}},
+		{"non-ascii target", Link{Target: `C:\Users\Zoë\ツール\launcher.exe`, WorkDir: `C:\Users\Zoë`, Description: "Über", ShowCommand: ShowMinimized}},
+		{"long target", Link{Target: long, ShowCommand: ShowNormal}},
+		{"unix target", Link{Target: "/usr/local/bin/launcher", ShowCommand: ShowNormal}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.link); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			got, err := Read(&buf)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if got != tt.link {
+				t.Errorf("round trip\n got %+v\nwant %+v", got, tt.link)
+			}
+		})
+	}
+}
+
+func TestWriteDefaultsShowCommand(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Link{Target: `C:\launcher.exe`}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ShowCommand != ShowNormal {
+		t.Errorf("ShowCommand = %d, want %d", got.ShowCommand, ShowNormal)
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		link Link
+	}{
+		{"empty target", Link{}},
+		{"long unc target", Link{Target: `\\server\share\` + strings.Repeat("x", 300)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Write(&bytes.Buffer{}, tt.link); err == nil {
+				t.Error("Write succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestReadRejectsCorruptLinks(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Link{Target: `C:\launcher.exe`, Arguments: "launch Work"}); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.Bytes()
+
+	// replace the environment variable block and the terminal block by a block header claiming almost 4 GiB
+	hugeBlock := append([]byte{}, valid[:len(valid)-4-environmentVariableSize]...)
+	hugeBlock = binary.LittleEndian.AppendUint32(hugeBlock, 0xFFFFFFF0)
+	hugeBlock = binary.LittleEndian.AppendUint32(hugeBlock, environmentVariableBlock)
+
+	hugeLinkInfo := append([]byte{}, valid...)
+	binary.LittleEndian.PutUint32(hugeLinkInfo[headerSize:], 0xFFFFFFF0)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:20]},
+		{"wrong clsid", append(append([]byte{}, valid[:4]...), make([]byte, len(valid)-4)...)},
+		{"oversized extra data block", hugeBlock},
+		{"oversized link info", hugeLinkInfo},
+		{"truncated strings", valid[:headerSize+0x60]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read(bytes.NewReader(tt.data)); err == nil {
+				t.Error("Read succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestParseHotKey(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   HotKey
+		string string
+	}{
+		{"", 0, ""},
+		{"Ctrl+Alt+P", 0x0650, "Ctrl+Alt+P"},
+		{"ctrl+shift+f5", 0x0374, "Ctrl+Shift+F5"},
+		{"Alt+0", 0x0430, "Alt+0"},
+		{"Control + Alt + F24", 0x0687, "Ctrl+Alt+F24"},
+		{"Ctrl+NumLock", 0x0290, "Ctrl+NumLock"},
+		{"Alt+ScrollLock", 0x0491, "Alt+ScrollLock"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseHotKey(tt.in)
+			if err != nil {
+				t.Fatalf("ParseHotKey(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseHotKey(%q) = %#04x, want %#04x", tt.in, uint16(got), uint16(tt.want))
+			}
+			if got.String() != tt.string {
+				t.Errorf("String() = %q, want %q", got.String(), tt.string)
+			}
+			again, err := ParseHotKey(got.String())
+			if err != nil || again != got {
+				t.Errorf("ParseHotKey(%q) = %#04x, %v, want %#04x", got.String(), uint16(again), err, uint16(got))
+			}
+		})
+	}
+}
+
+func TestParseHotKeyErrors(t *testing.T) {
+	for _, in := range []string{"P", "Shift+P", "Ctrl+F25", "Ctrl+F0", "Win+P", "Ctrl+Enter", "Ctrl+"} {
+		t.Run(in, func(t *testing.T) {
+			if _, err := ParseHotKey(in); err == nil {
+				t.Errorf("ParseHotKey(%q) succeeded, want an error", in)
+			}
+		})
+	}
+}
+
+func TestHotKeyStringUnknownKey(t *testing.T) {
+	if got := HotKey(0x0220).String(); got != "Ctrl+0x20" {
+		t.Errorf("String() = %q, want Ctrl+0x20", got)
+	}
+}
@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
 )
@@ -16,42 +23,282 @@ var (
 
 var shortcutCmd = &cobra.Command{
 	Use:   "shortcut",
-	Short: "Provides a way to create a new PowerShell launcher shortcut",
-	Long:  `This command provides a way to create a new PowerShell launcher shortcut.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		l.Logger.Info("Creating a new launcher shortcut")
-		// Do Stuff Here
+	Short: "Creates and manages launcher shortcuts",
+	Long: `This command creates shortcuts that launch a set, and lists, removes and regenerates the shortcuts
+recorded in the configuration.`,
+}
+
+var shortcutCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Creates a shortcut that launches a set or a list of profiles",
+	Long: `This command creates a shortcut that launches a set. Pass an existing set with --set, or profiles and
+a shell with --profiles and --shell to save them as a set named after the shortcut. A set with that name
+and other profiles is only replaced with --replace.`,
+	Args: cobra.NoArgs,
+	// errors here are about the set or the destination, not the command line
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.TrimSpace(cmd.Flag("name").Value.String())
+		destination, err := utils.ExpandPath(cmd.Flag("destination").Value.String())
+		if err != nil {
+			return err
+		}
+		if destination, err = filepath.Abs(destination); err != nil {
+			return err
+		}
+		set, save, err := shortcutSet(cmd, name)
+		if err != nil {
+			l.Logger.Error("Invalid shortcut set", "error", err)
+			return err
+		}
+		shortcut := utils.Shortcut{Name: name, Destination: destination, HotKey: cmd.Flag("hotkey").Value.String()}
+		l.Logger.Info("Creating shortcut", "shortcut", shortcut, "set", set.Name, "save", save)
+		replace, _ := cmd.Flags().GetBool("replace")
+		if save && !replace {
+			if err := utils.CheckShortcutSet(set); err != nil {
+				return fmt.Errorf("%w, pass --replace to replace it", err)
+			}
+		}
+		// planning checks the destination, the profiles and the hot key before the set is saved
+		plan, err := utils.PlanShortcut(set, shortcut)
+		if err != nil {
+			l.Logger.Error("Failed to plan shortcut", "error", err)
+			return err
+		}
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return writeDryRun(cmd, plan)
+		}
+		if save {
+			if err := utils.SaveShortcutSet(set, replace); err != nil {
+				l.Logger.Error("Failed to save set for shortcut", "error", err)
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved set %s\n", set.Name)
+		}
+		if err := utils.CreateShortcut(set, shortcut); err != nil {
+			l.Logger.Error("Failed to create shortcut", "error", err)
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", plan.Path)
+		return nil
+	},
+}
+
+var shortcutListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the shortcuts recorded in the configuration",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := utils.LoadConfig()
+		if err != nil {
+			l.Logger.Error("Failed to load configuration", "error", err)
+			return err
+		}
+		if len(config.Shortcuts) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No shortcuts recorded")
+			return nil
+		}
+		for _, shortcut := range config.Shortcuts {
+			set := "set " + shortcut.Set
+			if shortcut.Set == "" {
+				set = "no set"
+			}
+			path := utils.ShortcutFile(shortcut)
+			if _, err := os.Stat(path); err != nil {
+				path += " (missing)"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%3s  %s  [%s]  %s\n", shortcut.ID, shortcut.Name, set, path)
+		}
+		return nil
+	},
+}
+
+var shortcutRemoveCmd = &cobra.Command{
+	Use:   "remove <name|id>",
+	Short: "Deletes a shortcut and its record, the set is kept",
+	Args:  cobra.ExactArgs(1),
+	// errors here are about the shortcut, not the command line
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shortcut, err := utils.FindShortcut(args[0])
+		if err != nil {
+			return err
+		}
+		keepFile, _ := cmd.Flags().GetBool("keep-file")
+		if err := utils.RemoveShortcut(shortcut, keepFile); err != nil {
+			l.Logger.Error("Failed to remove shortcut", "error", err)
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", utils.ShortcutFile(shortcut))
+		return nil
+	},
+}
+
+var shortcutRegenerateCmd = &cobra.Command{
+	Use:   "regenerate [name|id...]",
+	Short: "Writes the shortcut files again from their sets, all of them by default",
+	Long: `This command writes the shortcut files again from their sets and the current launcher path,
+for example after the launcher moved or a set was renamed.`,
+	// errors here are about the shortcuts, not the command line
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var shortcuts []utils.Shortcut
+		if len(args) == 0 {
+			config, err := utils.LoadConfig()
+			if err != nil {
+				l.Logger.Error("Failed to load configuration", "error", err)
+				return err
+			}
+			shortcuts = append(shortcuts, config.Shortcuts...)
+		}
+		for _, arg := range args {
+			shortcut, err := utils.FindShortcut(arg)
+			if err != nil {
+				return err
+			}
+			shortcuts = append(shortcuts, shortcut)
+		}
+		failed := 0
+		for _, shortcut := range shortcuts {
+			if err := utils.RegenerateShortcut(shortcut); err != nil {
+				l.Logger.Error("Failed to regenerate shortcut", "name", shortcut.Name, "error", err)
+				fmt.Fprintln(cmd.ErrOrStderr(), "Warning:", err)
+				failed++
+				continue
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Regenerated %s\n", utils.ShortcutFile(shortcut))
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d shortcuts could not be regenerated", failed, len(shortcuts))
+		}
+		return nil
 	},
 }
 
+// shortcutSet returns the set the new shortcut launches, and whether it has to be saved first.
+func shortcutSet(cmd *cobra.Command, name string) (utils.Set, bool, error) {
+	setName := cmd.Flag("set").Value.String()
+	profiles := cmd.Flag("profiles").Value.String()
+	shell := cmd.Flag("shell").Value.String()
+	if setName != "" {
+		if profiles != "" || shell != "" {
+			return utils.Set{}, false, fmt.Errorf("--set cannot be combined with --profiles or --shell")
+		}
+		set, err := utils.FindSet(setName)
+		return set, false, err
+	}
+	if profiles == "" || shell == "" {
+		return utils.Set{}, false, fmt.Errorf("pass --set, or --profiles and --shell")
+	}
+	// the shortcut launches a set with the same name as the shortcut
+	set := utils.Set{Name: name, Shell: shell}
+	for _, profile := range utils.SplitProfiles(profiles) {
+		path, err := utils.ExpandPath(profile)
+		if err != nil {
+			return set, false, err
+		}
+		if path, err = filepath.Abs(path); err != nil {
+			return set, false, err
+		}
+		set.Profiles = append(set.Profiles, path)
+	}
+	return set, true, utils.ValidateSet(set)
+}
+
 var profilesCmd = &cobra.Command{
 	Use:   "profiles",
 	Short: "Loads the specified profile directly in the shell denoted by the profile",
 	Long:  `This command loads the specified profile directly in the shell denoted by the profile.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	// errors here are about the profiles, not the command line
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		l.Logger.Info("Loading the specified profile")
 		path := cmd.Flag("path").Value.String()
 		shell := cmd.Flag("shell").Value.String()
-		l.Logger.Debug("Profile path", "path", path, "shell", shell)
-		err := utils.LaunchProfilesFromCmd(path, shell)
+		workDir := cmd.Flag("workdir").Value.String()
+		l.Logger.Debug("Profile path", "path", path, "shell", shell, "workdir", workDir)
+		if print, _ := cmd.Flags().GetBool("print"); print {
+			req, _, err := utils.PrepareProfilesFromCmd(path, shell, workDir)
+			if err != nil {
+				l.Logger.Error("Failed to prepare profiles", "error", err)
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), req.Script)
+			return nil
+		}
+		mode, err := launchTarget(cmd, launcher.ModeWindow)
+		if err != nil {
+			l.Logger.Error("Invalid launch target", "error", err)
+			return err
+		}
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			req, warnings, err := utils.PrepareProfilesFromCmd(path, shell, workDir)
+			if err != nil {
+				l.Logger.Error("Failed to prepare profiles", "error", err)
+				return err
+			}
+			req.Mode = mode
+			plan, err := launcher.NewPlan(req)
+			if err != nil {
+				l.Logger.Error("Failed to plan launch", "error", err)
+				return err
+			}
+			plan.Warnings = append(plan.Warnings, warnings...)
+			if err := writeDryRun(cmd, plan); err != nil {
+				l.Logger.Error("Failed to print dry run", "error", err)
+				return err
+			}
+			return nil
+		}
+		wait, _ := cmd.Flags().GetBool("wait")
+		result, err := utils.LaunchProfilesFromCmd(deps.Launcher, path, shell, workDir, mode, wait, cmd.OutOrStdout())
 		if err != nil {
 			l.Logger.Error("Failed to launch profiles", "error", err)
+			var earlyErr *launcher.EarlyExitError
+			if mode == launcher.ModeInline || errors.As(err, &earlyErr) {
+				exitCode = launcher.ExitCode(err)
+			}
+			if earlyErr != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+			}
+			return nil
+		}
+		if result.Waited && result.ExitCode > 0 {
+			exitCode = result.ExitCode
 		}
 		l.Logger.Info("Profiles loaded successfully")
+		return nil
 	},
 }
 
 func init() {
-	// flags for the shortcut command
-	shortcutCmd.Flags().StringP("name", "n", "", "The name of the shortcut")
-	shortcutCmd.Flags().StringP("path", "p", "", "The path to the PowerShell script")
-	// command configs
-	shortcutCmd.MarkFlagRequired("name")
-	shortcutCmd.MarkFlagRequired("path")
+	// flags for the shortcut commands
+	shortcutCreateCmd.Flags().StringP("name", "n", "", "The name of the shortcut")
+	shortcutCreateCmd.Flags().StringP("destination", "d", "", "The folder the shortcut is created in")
+	shortcutCreateCmd.Flags().String("set", "", "The set the shortcut launches")
+	shortcutCreateCmd.Flags().StringP("profiles", "p", "", "Comma separated profiles saved as a set named after the shortcut")
+	shortcutCreateCmd.Flags().StringP("shell", "s", "", "The shell of the profiles")
+	shortcutCreateCmd.Flags().Bool("replace", false, "Replace a set named after the shortcut that has other profiles")
+	shortcutCreateCmd.Flags().String("hotkey", "", "The hot key of the shortcut, such as Ctrl+Alt+P")
+	shortcutCreateCmd.Flags().Bool("dry-run", false, "Print the shortcut target, arguments and working directory without creating it")
+	shortcutCreateCmd.Flags().Bool("json", false, "Print the dry run as JSON")
+	shortcutCreateCmd.MarkFlagRequired("name")
+	shortcutCreateCmd.MarkFlagRequired("destination")
+	shortcutRemoveCmd.Flags().Bool("keep-file", false, "Only remove the record, keep the shortcut file")
+	shortcutCmd.AddCommand(shortcutCreateCmd)
+	shortcutCmd.AddCommand(shortcutListCmd)
+	shortcutCmd.AddCommand(shortcutRemoveCmd)
+	shortcutCmd.AddCommand(shortcutRegenerateCmd)
 
 	// flags for the profiles command
 	profilesCmd.Flags().StringP("path", "p", "", "The path to the profile")
 	profilesCmd.Flags().StringP("shell", "s", "", "The shell to use")
+	profilesCmd.Flags().StringP("workdir", "w", "", "The starting directory of the shell, overrides the profile WORKDIR headers")
+	profilesCmd.Flags().Bool("print", false, "Print the merged script that would be launched without launching it")
+	profilesCmd.Flags().Bool("inline", false, "Run the shell in the current terminal and exit with its exit code")
+	profilesCmd.Flags().String("target", "", "Where to open the shell: window, inline, tmux or tmux-split")
+	profilesCmd.Flags().Bool("dry-run", false, "Print the shell, arguments, environment, working directory and script without launching")
+	profilesCmd.Flags().Bool("json", false, "Print the dry run as JSON")
+	profilesCmd.Flags().Bool("wait", false, "Wait for the shell window to close and report how long it ran")
 	// command configs
 	profilesCmd.MarkFlagRequired("path")
 	profilesCmd.MarkFlagRequired("shell")
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// resetCommands sets the flags of every command back to their defaults, cobra keeps them between runs.
+func resetCommands() {
+	rootCmd.SetArgs(nil)
+	rootCmd.SetOut(nil)
+	rootCmd.SetErr(nil)
+	exitCode = 0
+	for _, command := range rootCmd.Commands() {
+		command.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Value.Set(flag.DefValue)
+			flag.Changed = false
+		})
+	}
+}
+
+// execute runs the root command with args from fresh flags and returns its output.
+func execute(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	resetCommands()
+	t.Cleanup(resetCommands)
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	err := rootCmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestProfilesPrintFailure(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "Missing.Profile.sh")
+	stdout, stderr, err := execute(t, "profiles", "--print", "--path", missing, "--shell", "sh")
+	if err == nil {
+		t.Fatal("profiles --print succeeded for a missing profile")
+	}
+	if stdout != "" {
+		t.Errorf("stdout = %q, want no script", stdout)
+	}
+	if !strings.Contains(stderr, "Error:") || strings.Contains(stderr, "Usage:") {
+		t.Errorf("stderr = %q, want the error without the usage", stderr)
+	}
+}
+
+func TestProfilesDryRunFailure(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "Missing.Profile.sh")
+	for _, args := range [][]string{
+		{"profiles", "--dry-run", "--path", missing, "--shell", "sh"},
+		{"profiles", "--dry-run", "--json", "--path", missing, "--shell", "sh"},
+		{"profiles", "--dry-run", "--target", "tab", "--path", missing, "--shell", "sh"},
+	} {
+		stdout, stderr, err := execute(t, args...)
+		if err == nil {
+			t.Errorf("%v succeeded, want an error", args)
+		}
+		if stdout != "" || !strings.Contains(stderr, "Error:") {
+			t.Errorf("%v printed %q and %q, want only the error", args, stdout, stderr)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -24,6 +26,19 @@ type DefaultItemStyles struct {
 	Checked     lipgloss.Style
 }
 
+// ValidationIssue is a problem found in a profile. Line is 1-based, 0 when the issue is not tied to a line.
+type ValidationIssue struct {
+	Line    int
+	Message string
+}
+
+func (v ValidationIssue) String() string {
+	if v.Line > 0 {
+		return fmt.Sprintf("line %d: %s", v.Line, v.Message)
+	}
+	return v.Message
+}
+
 // ProfileItem represents a profile item in the list
 type ProfileItem struct {
 	ItemTitle           string
@@ -37,6 +52,10 @@ type ProfileItem struct {
 	IsValidShellVersion bool
 	IsValidDescription  bool
 	IsSelected          bool
+	Env                 []string
+	WorkDir             string
+	Args                []string
+	Issues              []ValidationIssue
 }
 
 func (p ProfileItem) Title() string       { return p.ItemTitle }
@@ -45,8 +64,7 @@ func (p ProfileItem) FilterValue() string { return p.Name }
 
 func (p ProfileItem) GetPath() string { return p.Path }
 func (p ProfileItem) GetName() string {
-	n := strings.Split(p.Path, "\\")
-	p.Name = n[len(n)-1]
+	p.Name = filepath.Base(p.Path)
 	return p.Name
 }
 func (p ProfileItem) GetDescription() string       { return strings.TrimLeft(p.ItemDescription, " ") }
@@ -59,6 +77,16 @@ func (p ProfileItem) IsValidProfile() bool {
 }
 func (p ProfileItem) IsSelectedProfile() bool { return p.IsSelected }
 
+// FirstIssueLine returns the line of the first validation issue that has one, or 0.
+func (p ProfileItem) FirstIssueLine() int {
+	for _, issue := range p.Issues {
+		if issue.Line > 0 {
+			return issue.Line
+		}
+	}
+	return 0
+}
+
 // ShellItem represents a shell item in the list
 
 type ShellItem struct {
@@ -68,8 +96,28 @@ type ShellItem struct {
 	ItemDescription string
 	ShortName       string
 	ShortNames      []string
+	Kind            string
 	ProfilePaths    []string
+	Env             []string
+	WorkDir         string
+	Args            []string
+	Edition         string
+	Version         string
+	Available       bool
+	Incompatible    []string
+	Warnings        []string
 	IsSelected      bool
+	// Launch is the state of the last launch from the shell view.
+	Launch LaunchState
+}
+
+// LaunchState is shown next to a shell while it launches and once it started or failed.
+type LaunchState struct {
+	Running bool
+	// Spinner is the current frame of the shell's spinner while it launches.
+	Spinner string
+	Failed  bool
+	Message string
 }
 
 // Implement the list.Item interface for ShellItem
@@ -92,5 +140,13 @@ func (m ShellItem) GetShortName() string {
 }
 func (m ShellItem) IsSelectedShell() bool { return m.IsSelected }
 func (m ShellItem) Title() string         { return m.ItemTitle }
-func (m ShellItem) Description() string   { return m.ShortName }
-func (m ShellItem) FilterValue() string   { return m.ItemTitle }
+func (m ShellItem) Description() string {
+	if m.ItemDescription == "" {
+		return m.ShortName
+	}
+	return m.ItemDescription
+}
+func (m ShellItem) FilterValue() string { return m.ItemTitle }
+
+// Launchable reports whether the shell is installed and can run its profiles.
+func (m ShellItem) Launchable() bool { return m.Available && len(m.Incompatible) == 0 }
@@ -2,23 +2,56 @@ package codeviewerview
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/highlight"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/styles"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
 )
 
+type inputMode int
+
+const (
+	normalMode inputMode = iota
+	searchMode
+	gotoMode
+)
+
+// match is a search match, Start and End are byte offsets into the plain line.
+type match struct {
+	Line  int
+	Start int
+	End   int
+}
+
 type model struct {
 	codeviewer  viewport.Model
-	profilePath string
+	title       string
 	viewChanger view.ViewChanger
 	windowSize  tea.WindowSizeMsg
 	help        string
+	lines       []string
+	// kind is the shell kind of the source, only PowerShell is highlighted
+	kind         string
+	highlighted  []string
+	highlight    bool
+	lineNumbers  bool
+	mode         inputMode
+	input        textinput.Model
+	query        string
+	matches      []match
+	currentMatch int
+	status       string
 }
 
 const useHighPerformanceRenderer = false
@@ -31,33 +64,64 @@ func New(path string, windowSize tea.WindowSizeMsg, viewChanger view.ViewChanger
 		content = "Failed to load profile content"
 	}
 	l.Logger.Info("Loaded profile content", "content", content)
+	return NewFromContent(path, content, launcher.ProfileKind(path), windowSize, viewChanger)
+}
 
-	title := styles.TitleStyle.Render(path)
+// NewAtLine opens a profile scrolled to the 1-based line, such as the line of a validation issue.
+func NewAtLine(path string, line int, windowSize tea.WindowSizeMsg, viewChanger view.ViewChanger) model {
+	m := New(path, windowSize, viewChanger)
+	m.lineNumbers = true
+	m.render()
+	m.gotoLine(line)
+	return m
+}
+
+// NewFromContent shows content that is not read from a profile file, such as a generated script of the shell kind.
+// Content that is not a script, such as a dry run, has no kind.
+func NewFromContent(title string, content string, kind string, windowSize tea.WindowSizeMsg, viewChanger view.ViewChanger) model {
+	renderedTitle := styles.TitleStyle.Render(title)
 	vp := viewport.New(windowSize.Width, windowSize.Height)
-	line := strings.Repeat("─", max(0, vp.Width-lipgloss.Width(title)))
-	header := lipgloss.JoinHorizontal(lipgloss.Center, title, line)
+	line := strings.Repeat("─", max(0, vp.Width-lipgloss.Width(renderedTitle)))
+	header := lipgloss.JoinHorizontal(lipgloss.Center, renderedTitle, line)
 	headerHeight := lipgloss.Height(header)
 	info := styles.ViewPortInfoStyle.Render(fmt.Sprintf("%3.f%%", vp.ScrollPercent()*100))
 	footer := lipgloss.JoinHorizontal(lipgloss.Center, line, info)
 	footerHeight := lipgloss.Height(footer)
-	help := styles.HelpStyle.Render("↑/k: up, ↓/j: down, u: ½ page up, d: ½ page down, Ctrl+←: back")
+	help := styles.HelpStyle.Render("↑/k: up, ↓/j: down, u: ½ page up, d: ½ page down, /: search, n/N: next/previous match, :: go to line, g: line numbers, s: highlighting, Ctrl+←: back")
 	helpHeight := lipgloss.Height(help)
 	verticalMarginHeight := headerHeight + footerHeight + helpHeight
 	vp.YPosition = headerHeight
 	vp.HighPerformanceRendering = useHighPerformanceRenderer
-	vp.SetContent(content)
 	vp.VisibleLineCount()
 	vp.KeyMap = viewport.DefaultKeyMap()
 	vp.MouseWheelEnabled = true
 
 	vp.Height = windowSize.Height - verticalMarginHeight
-	return model{
-		codeviewer:  vp,
-		profilePath: path,
-		viewChanger: viewChanger,
-		windowSize:  windowSize,
-		help:        help,
+	input := textinput.New()
+	input.CharLimit = 256
+	m := model{
+		codeviewer:   vp,
+		title:        title,
+		viewChanger:  viewChanger,
+		windowSize:   windowSize,
+		help:         help,
+		lines:        strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n"),
+		kind:         kind,
+		highlight:    highlightEnabled(),
+		input:        input,
+		currentMatch: -1,
+	}
+	m.render()
+	return m
+}
+
+func highlightEnabled() bool {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		l.Logger.Warn("Failed to load configuration, highlighting enabled", "error", err)
+		return true
 	}
+	return config.Viewer.Highlight
 }
 
 func (m model) Init() tea.Cmd {
@@ -70,22 +134,208 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.windowSize = msg
 		m.codeviewer.Width = msg.Width
 		m.codeviewer.Height = msg.Height - m.codeviewer.YPosition
+	case tea.KeyMsg:
+		if m.mode != normalMode {
+			return m.updateInput(msg)
+		}
+		switch msg.String() {
+		case "s":
+			if m.kind != launcher.KindPowerShell {
+				m.status = "Highlighting is only available for PowerShell"
+				return m, nil
+			}
+			m.highlight = !m.highlight
+			l.Logger.Debug("Toggled syntax highlighting", "highlight", m.highlight)
+			m.render()
+			return m, nil
+		case "g":
+			m.lineNumbers = !m.lineNumbers
+			m.render()
+			return m, nil
+		case "/":
+			return m, m.startInput(searchMode, "/")
+		case ":":
+			return m, m.startInput(gotoMode, ":")
+		case "n":
+			m.nextMatch(1)
+			return m, nil
+		case "N":
+			m.nextMatch(-1)
+			return m, nil
+		}
 	}
 	var cmd tea.Cmd
 	m.codeviewer, cmd = m.codeviewer.Update(msg)
 	return m, cmd
 }
 
+func (m *model) startInput(mode inputMode, prompt string) tea.Cmd {
+	m.mode = mode
+	m.status = ""
+	m.input.Prompt = prompt
+	m.input.SetValue("")
+	return m.input.Focus()
+}
+
+func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = normalMode
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.input.Value())
+		mode := m.mode
+		m.mode = normalMode
+		m.input.Blur()
+		switch mode {
+		case searchMode:
+			m.search(value)
+		case gotoMode:
+			line, err := strconv.Atoi(value)
+			if err != nil {
+				m.status = "Invalid line number: " + value
+				return m, nil
+			}
+			m.gotoLine(line)
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// search finds all case-insensitive matches of query and scrolls to the first one after the current position.
+func (m *model) search(query string) {
+	l.Logger.Debug("Searching code viewer", "query", query)
+	m.query = query
+	m.matches = nil
+	m.currentMatch = -1
+	if query != "" {
+		m.matches = findMatches(m.lines, query)
+	}
+	if len(m.matches) == 0 {
+		if query != "" {
+			m.status = "No matches for " + query
+		}
+		m.render()
+		return
+	}
+	m.currentMatch = 0
+	for i, found := range m.matches {
+		if found.Line >= m.codeviewer.YOffset {
+			m.currentMatch = i
+			break
+		}
+	}
+	m.showCurrentMatch()
+}
+
+// findMatches returns the case-insensitive matches of query in the lines. The offsets point into the lines themselves,
+// also where changing the case would change their length.
+func findMatches(lines []string, query string) []match {
+	pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+	var matches []match
+	for i, line := range lines {
+		for _, found := range pattern.FindAllStringIndex(line, -1) {
+			matches = append(matches, match{Line: i, Start: found[0], End: found[1]})
+		}
+	}
+	return matches
+}
+
+func (m *model) nextMatch(step int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.currentMatch = (m.currentMatch + step + len(m.matches)) % len(m.matches)
+	m.showCurrentMatch()
+}
+
+func (m *model) showCurrentMatch() {
+	m.status = fmt.Sprintf("Match %d/%d", m.currentMatch+1, len(m.matches))
+	m.render()
+	line := m.matches[m.currentMatch].Line
+	if line < m.codeviewer.YOffset || line >= m.codeviewer.YOffset+m.codeviewer.Height {
+		m.codeviewer.SetYOffset(max(0, line-m.codeviewer.Height/2))
+	}
+}
+
+// gotoLine scrolls so the 1-based line is at the top of the viewer.
+func (m *model) gotoLine(line int) {
+	if line < 1 || line > len(m.lines) {
+		m.status = fmt.Sprintf("Line %d is out of range (1-%d)", line, len(m.lines))
+		return
+	}
+	m.status = fmt.Sprintf("Line %d", line)
+	m.codeviewer.SetYOffset(line - 1)
+}
+
+// render rebuilds the viewport content from the lines, highlighting, search matches and gutter.
+func (m *model) render() {
+	highlighted := m.highlight && m.kind == launcher.KindPowerShell
+	if highlighted && m.highlighted == nil {
+		m.highlighted = highlight.PowerShell(strings.Join(m.lines, "\n"))
+	}
+	matchesByLine := make(map[int][]int)
+	for i, found := range m.matches {
+		matchesByLine[found.Line] = append(matchesByLine[found.Line], i)
+	}
+	gutterWidth := len(strconv.Itoa(len(m.lines)))
+
+	rendered := make([]string, len(m.lines))
+	for i, line := range m.lines {
+		if highlighted && i < len(m.highlighted) {
+			line = m.highlighted[i]
+		}
+		if len(matchesByLine[i]) > 0 {
+			line = highlight.Overlay(line, m.matchMarks(matchesByLine[i]))
+		}
+		if m.lineNumbers {
+			line = styles.LineNumberStyle.Render(fmt.Sprintf("%*d │ ", gutterWidth, i+1)) + line
+		}
+		rendered[i] = line
+	}
+	m.codeviewer.SetContent(strings.Join(rendered, "\n"))
+}
+
+// matchMarks styles the matches of a line, laid over its highlighting.
+func (m *model) matchMarks(matchIndexes []int) []highlight.Mark {
+	marks := make([]highlight.Mark, len(matchIndexes))
+	for j, i := range matchIndexes {
+		found := m.matches[i]
+		style := styles.SearchMatchStyle
+		if i == m.currentMatch {
+			style = styles.CurrentSearchMatchStyle
+		}
+		marks[j] = highlight.Mark{Start: found.Start, End: found.End, Style: style}
+	}
+	return marks
+}
+
 func (m model) View() string {
-	title := styles.TitleStyle.Render(m.profilePath)
+	title := styles.TitleStyle.Render(m.title)
 	line := strings.Repeat("─", max(0, m.codeviewer.Width-lipgloss.Width(title)))
 	header := lipgloss.JoinHorizontal(lipgloss.Center, title, line)
 	finfo := styles.ViewPortInfoStyle.Render(fmt.Sprintf("%3.f%%", m.codeviewer.ScrollPercent()*100))
-	fline := strings.Repeat("─", max(0, m.codeviewer.Width-lipgloss.Width(finfo)))
-	footer := lipgloss.JoinVertical(lipgloss.Left, lipgloss.JoinHorizontal(lipgloss.Center, fline, finfo), m.help)
+	status := m.status
+	if m.mode != normalMode {
+		status = m.input.View()
+	}
+	fline := strings.Repeat("─", max(0, m.codeviewer.Width-lipgloss.Width(finfo)-lipgloss.Width(status)))
+	footer := lipgloss.JoinVertical(lipgloss.Left, lipgloss.JoinHorizontal(lipgloss.Center, status, fline, finfo), m.help)
 	return lipgloss.JoinVertical(lipgloss.Left, header, m.codeviewer.View(), footer)
 }
 
+// FilterState reports the search and go to line prompts as filtering so global keys are typed into them.
+func (m model) FilterState() list.FilterState {
+	if m.mode != normalMode {
+		return list.Filtering
+	}
+	return list.Unfiltered
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -0,0 +1,86 @@
+package codeviewerview
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestFindMatches(t *testing.T) {
+	lines := []string{
+		"Write-Host 'hello'",
+		"İstanbul istanbul",
+		"$a.b = 'A.B' # a+b",
+		"",
+	}
+	tests := []struct {
+		query string
+		want  []match
+	}{
+		{"write", []match{{Line: 0, Start: 0, End: 5}}},
+		{"L", []match{{Line: 0, Start: 14, End: 15}, {Line: 0, Start: 15, End: 16}, {Line: 1, Start: 8, End: 9}, {Line: 1, Start: 17, End: 18}}},
+		// İ is two bytes and its lowercase form three, the offsets still point into the line itself
+		{"stanbul", []match{{Line: 1, Start: 2, End: 9}, {Line: 1, Start: 11, End: 18}}},
+		{"İstanbul", []match{{Line: 1, Start: 0, End: 9}}},
+		// the query is matched literally
+		{"a.b", []match{{Line: 2, Start: 1, End: 4}, {Line: 2, Start: 8, End: 11}}},
+		{"a+b", []match{{Line: 2, Start: 15, End: 18}}},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		if got := findMatches(lines, tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("findMatches(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightOnlyPowerShell(t *testing.T) {
+	previous := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(previous) })
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	content := "# note\n$x = 'a'\n"
+	size := tea.WindowSizeMsg{Width: 80, Height: 20}
+	profile := func(name string) model {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return New(path, size, nil)
+	}
+
+	tests := []struct {
+		name        string
+		viewer      model
+		highlighted bool
+	}{
+		{"PowerShell profile", profile("Dev.Profile.ps1"), true},
+		{"bash profile", profile("Dev.Profile.sh"), false},
+		{"PowerShell script", NewFromContent("Preview", content, "powershell", size, nil), true},
+		{"zsh script", NewFromContent("Preview", content, "zsh", size, nil), false},
+		{"dry run", NewFromContent("Dry run", content, "", size, nil), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.viewer
+			m.highlight = true
+			m.render()
+			if got := strings.Contains(m.codeviewer.View(), "\x1b["); got != tt.highlighted {
+				t.Errorf("highlighted = %v, want %v", got, tt.highlighted)
+			}
+			if !tt.highlighted {
+				updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+				if status := updated.(model).status; !strings.Contains(status, "only available for PowerShell") {
+					t.Errorf("status after s = %q, want highlighting unavailable", status)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package common
 
 import (
+	"sort"
+
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -17,3 +19,28 @@ var Keymap = KeyMap{
 		key.WithHelp("alt+left", "back"),
 	),
 }
+
+// SortedSelection returns the selected list indexes in list order.
+func SortedSelection(selected map[int]struct{}) []int {
+	indexes := make([]int, 0, len(selected))
+	for i := range selected {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// SetSavedMsg is sent to the previous view after a profile set was saved.
+type SetSavedMsg struct {
+	Name string
+	// Warning is set when the set was saved but its shortcuts could not be updated.
+	Warning string
+}
+
+// Status is the status message reporting the saved set.
+func (msg SetSavedMsg) Status() string {
+	if msg.Warning != "" {
+		return "Saved set " + msg.Name + "; Warning: " + msg.Warning
+	}
+	return "Saved set " + msg.Name
+}
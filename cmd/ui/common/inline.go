@@ -0,0 +1,41 @@
+package common
+
+import (
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+)
+
+// inlineLaunch lets bubbletea hand the terminal to a launcher running an inline shell.
+type inlineLaunch struct {
+	launcher launcher.Launcher
+	req      launcher.Request
+	result   launcher.Result
+}
+
+func (c *inlineLaunch) Run() error {
+	var err error
+	c.result, err = c.launcher.Launch(c.req)
+	return err
+}
+
+func (c *inlineLaunch) SetStdin(r io.Reader)  { c.req.Stdin = r }
+func (c *inlineLaunch) SetStdout(w io.Writer) { c.req.Stdout = w }
+func (c *inlineLaunch) SetStderr(w io.Writer) { c.req.Stderr = w }
+
+// LaunchInline suspends the program, runs the shell in the terminal and resumes when it exits.
+func LaunchInline(l launcher.Launcher, req launcher.Request, fn func(launcher.Result, error) tea.Msg) tea.Cmd {
+	req.Mode = launcher.ModeInline
+	c := &inlineLaunch{launcher: l, req: req}
+	return tea.Exec(c, func(err error) tea.Msg {
+		return fn(c.result, err)
+	})
+}
+
+// InlineLaunchFinishedMsg is sent when an inline shell started from a view exits.
+type InlineLaunchFinishedMsg struct {
+	Shell  string
+	Result launcher.Result
+	Err    error
+}
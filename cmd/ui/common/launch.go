@@ -0,0 +1,43 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+)
+
+// LaunchFinishedMsg is sent when a shell started by Launch returns.
+type LaunchFinishedMsg struct {
+	Shell    string
+	Result   launcher.Result
+	Err      error
+	Warnings []string
+}
+
+// Launch starts the shell in the background, the launcher watches it for an early exit and the view keeps
+// responding meanwhile.
+func Launch(l launcher.Launcher, req launcher.Request, shell string, warnings []string) tea.Cmd {
+	return func() tea.Msg {
+		result, err := l.Launch(req)
+		return LaunchFinishedMsg{Shell: shell, Result: result, Err: err, Warnings: warnings}
+	}
+}
+
+// Status describes the launch for the status bar.
+func (msg LaunchFinishedMsg) Status() string {
+	status := fmt.Sprintf("%s %s", msg.Shell, msg.Result)
+	var earlyErr *launcher.EarlyExitError
+	switch {
+	case errors.As(msg.Err, &earlyErr):
+		status = fmt.Sprintf("%s crashed: %s", msg.Shell, strings.ReplaceAll(msg.Err.Error(), "\n", " "))
+	case msg.Err != nil:
+		status = fmt.Sprintf("%s failed to start: %v", msg.Shell, msg.Err)
+	}
+	if len(msg.Warnings) > 0 {
+		status += "; Warning: " + strings.Join(msg.Warnings, "; ")
+	}
+	return status
+}
@@ -0,0 +1,228 @@
+package diffview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/styles"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
+)
+
+const diffContext = 3
+
+type model struct {
+	diffviewer   viewport.Model
+	leftPath     string
+	rightPath    string
+	lines        []utils.DiffLine
+	hunks        []utils.DiffHunk
+	hunkOffsets  []int
+	currentHunk  int
+	sideBySide   bool
+	viewChanger  view.ViewChanger
+	windowSize   tea.WindowSizeMsg
+	help         string
+	marginHeight int
+}
+
+func New(leftPath string, rightPath string, windowSize tea.WindowSizeMsg, viewChanger view.ViewChanger) model {
+	l.Logger.Info("Initializing diff viewer", "left", leftPath, "right", rightPath)
+	left, err := utils.LoadProfileContent(leftPath)
+	if err != nil {
+		l.Logger.Error("Failed to load profile content", "path", leftPath, "error", err)
+		left = "Failed to load profile content"
+	}
+	right, err := utils.LoadProfileContent(rightPath)
+	if err != nil {
+		l.Logger.Error("Failed to load profile content", "path", rightPath, "error", err)
+		right = "Failed to load profile content"
+	}
+	lines := utils.DiffLines(utils.SplitLines(left), utils.SplitLines(right))
+	hunks := utils.DiffHunks(lines, diffContext)
+	l.Logger.Debug("Computed profile diff", "lines", len(lines), "hunks", len(hunks))
+
+	help := styles.HelpStyle.Render("↑/k: up, ↓/j: down, n/N: next/previous change, t: toggle side-by-side, Ctrl+←: back")
+	m := model{
+		leftPath:    leftPath,
+		rightPath:   rightPath,
+		lines:       lines,
+		hunks:       hunks,
+		currentHunk: -1,
+		viewChanger: viewChanger,
+		windowSize:  windowSize,
+		help:        help,
+	}
+	m.marginHeight = lipgloss.Height(m.header()) + lipgloss.Height(m.footer()) + lipgloss.Height(help)
+	m.diffviewer = viewport.New(windowSize.Width, max(0, windowSize.Height-m.marginHeight))
+	m.diffviewer.YPosition = lipgloss.Height(m.header())
+	m.diffviewer.KeyMap = viewport.DefaultKeyMap()
+	m.diffviewer.MouseWheelEnabled = true
+	m.render()
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.windowSize = msg
+		m.diffviewer.Width = msg.Width
+		m.diffviewer.Height = max(0, msg.Height-m.marginHeight)
+		m.render()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "t":
+			m.sideBySide = !m.sideBySide
+			m.render()
+			m.scrollToHunk()
+			return m, nil
+		case "n":
+			if len(m.hunks) > 0 {
+				m.currentHunk = (m.currentHunk + 1) % len(m.hunks)
+				m.scrollToHunk()
+			}
+			return m, nil
+		case "N":
+			if len(m.hunks) > 0 {
+				m.currentHunk--
+				if m.currentHunk < 0 {
+					m.currentHunk = len(m.hunks) - 1
+				}
+				m.scrollToHunk()
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.diffviewer, cmd = m.diffviewer.Update(msg)
+	return m, cmd
+}
+
+func (m model) View() string {
+	return lipgloss.JoinVertical(lipgloss.Left, m.header(), m.diffviewer.View(), m.footer())
+}
+
+func (m model) header() string {
+	title := styles.TitleStyle.Render(fmt.Sprintf("%s ↔ %s", m.leftPath, m.rightPath))
+	line := strings.Repeat("─", max(0, m.windowSize.Width-lipgloss.Width(title)))
+	return lipgloss.JoinHorizontal(lipgloss.Center, title, line)
+}
+
+func (m model) footer() string {
+	layout := "unified"
+	if m.sideBySide {
+		layout = "side-by-side"
+	}
+	position := fmt.Sprintf("%d changes", len(m.hunks))
+	if m.currentHunk >= 0 {
+		position = fmt.Sprintf("change %d/%d", m.currentHunk+1, len(m.hunks))
+	}
+	info := styles.ViewPortInfoStyle.Render(fmt.Sprintf("%s, %s, %3.f%%", layout, position, m.diffviewer.ScrollPercent()*100))
+	line := strings.Repeat("─", max(0, m.windowSize.Width-lipgloss.Width(info)))
+	return lipgloss.JoinVertical(lipgloss.Left, lipgloss.JoinHorizontal(lipgloss.Center, line, info), m.help)
+}
+
+func (m *model) scrollToHunk() {
+	if m.currentHunk < 0 || m.currentHunk >= len(m.hunkOffsets) {
+		return
+	}
+	m.diffviewer.SetYOffset(m.hunkOffsets[m.currentHunk])
+}
+
+// render rebuilds the viewport content for the current layout and records where each hunk starts.
+func (m *model) render() {
+	if len(m.hunks) == 0 {
+		m.hunkOffsets = nil
+		m.diffviewer.SetContent(styles.DiffGutterStyle.Render("Profiles are identical"))
+		return
+	}
+	var rows []string
+	m.hunkOffsets = make([]int, len(m.hunks))
+	for i, hunk := range m.hunks {
+		m.hunkOffsets[i] = len(rows)
+		rows = append(rows, styles.DiffHunkStyle.Render(hunk.Header()))
+		if m.sideBySide {
+			rows = append(rows, m.renderSideBySide(m.lines[hunk.Start:hunk.End])...)
+		} else {
+			rows = append(rows, m.renderUnified(m.lines[hunk.Start:hunk.End])...)
+		}
+	}
+	m.diffviewer.SetContent(strings.Join(rows, "\n"))
+}
+
+func (m *model) renderUnified(lines []utils.DiffLine) []string {
+	rows := make([]string, 0, len(lines))
+	for _, line := range lines {
+		text := expandTabs(line.Text)
+		switch line.Kind {
+		case utils.DiffAdded:
+			rows = append(rows, styles.DiffGutterStyle.Render(fmt.Sprintf("%4s %4d ", "", line.NewLine))+styles.DiffAddedStyle.Render("+"+text))
+		case utils.DiffRemoved:
+			rows = append(rows, styles.DiffGutterStyle.Render(fmt.Sprintf("%4d %4s ", line.OldLine, ""))+styles.DiffRemovedStyle.Render("-"+text))
+		default:
+			rows = append(rows, styles.DiffGutterStyle.Render(fmt.Sprintf("%4d %4d ", line.OldLine, line.NewLine))+" "+text)
+		}
+	}
+	return rows
+}
+
+func (m *model) renderSideBySide(lines []utils.DiffLine) []string {
+	columnWidth := max(10, (m.diffviewer.Width-3)/2)
+	var rows []string
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == utils.DiffEqual {
+			left := sideBySideCell(lines[i].OldLine, lines[i].Text, columnWidth, lipgloss.NewStyle())
+			right := sideBySideCell(lines[i].NewLine, lines[i].Text, columnWidth, lipgloss.NewStyle())
+			rows = append(rows, left+styles.DiffGutterStyle.Render(" │ ")+right)
+			i++
+			continue
+		}
+		// pair up a block of removed lines with the added lines that follow it
+		var removed, added []utils.DiffLine
+		for ; i < len(lines) && lines[i].Kind == utils.DiffRemoved; i++ {
+			removed = append(removed, lines[i])
+		}
+		for ; i < len(lines) && lines[i].Kind == utils.DiffAdded; i++ {
+			added = append(added, lines[i])
+		}
+		for j := 0; j < max(len(removed), len(added)); j++ {
+			left := strings.Repeat(" ", columnWidth)
+			right := ""
+			if j < len(removed) {
+				left = sideBySideCell(removed[j].OldLine, removed[j].Text, columnWidth, styles.DiffRemovedStyle)
+			}
+			if j < len(added) {
+				right = sideBySideCell(added[j].NewLine, added[j].Text, columnWidth, styles.DiffAddedStyle)
+			}
+			rows = append(rows, left+styles.DiffGutterStyle.Render(" │ ")+right)
+		}
+	}
+	return rows
+}
+
+func sideBySideCell(lineNumber int, text string, width int, style lipgloss.Style) string {
+	gutter := fmt.Sprintf("%4d ", lineNumber)
+	text = ansi.Truncate(expandTabs(text), max(0, width-len(gutter)), "…")
+	padding := strings.Repeat(" ", max(0, width-len(gutter)-ansi.StringWidth(text)))
+	return styles.DiffGutterStyle.Render(gutter) + style.Render(text) + padding
+}
+
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", "    ")
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
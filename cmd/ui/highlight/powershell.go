@@ -0,0 +1,276 @@
+package highlight
+
+import (
+	"strings"
+	"unicode"
+)
+
+type TokenKind int
+
+const (
+	Text TokenKind = iota
+	Keyword
+	Variable
+	String
+	HereString
+	Comment
+	Cmdlet
+	Operator
+	Parameter
+	Number
+)
+
+// Token is a run of source text. Comments, strings and here-strings may span several lines.
+type Token struct {
+	Kind TokenKind
+	Text string
+}
+
+var powershellKeywords = map[string]struct{}{
+	"begin": {}, "break": {}, "catch": {}, "class": {}, "continue": {}, "data": {}, "define": {},
+	"do": {}, "dynamicparam": {}, "else": {}, "elseif": {}, "end": {}, "enum": {}, "exit": {},
+	"filter": {}, "finally": {}, "for": {}, "foreach": {}, "from": {}, "function": {}, "hidden": {},
+	"if": {}, "in": {}, "inlinescript": {}, "param": {}, "parallel": {}, "process": {}, "return": {},
+	"sequence": {}, "static": {}, "switch": {}, "throw": {}, "trap": {}, "try": {}, "until": {},
+	"using": {}, "var": {}, "while": {}, "workflow": {},
+}
+
+var powershellOperators = map[string]struct{}{
+	"eq": {}, "ne": {}, "gt": {}, "ge": {}, "lt": {}, "le": {}, "like": {}, "notlike": {},
+	"match": {}, "notmatch": {}, "contains": {}, "notcontains": {}, "in": {}, "notin": {},
+	"replace": {}, "split": {}, "join": {}, "and": {}, "or": {}, "xor": {}, "not": {},
+	"band": {}, "bor": {}, "bxor": {}, "bnot": {}, "shl": {}, "shr": {}, "is": {}, "isnot": {},
+	"as": {}, "f": {},
+}
+
+// TokenizePowerShell splits PowerShell source into tokens. Concatenating the token text returns the source unchanged.
+func TokenizePowerShell(src string) []Token {
+	lx := &lexer{src: []rune(src)}
+	for lx.pos < len(lx.src) {
+		lx.next()
+	}
+	lx.flushText()
+	return lx.tokens
+}
+
+type lexer struct {
+	src       []rune
+	pos       int
+	textStart int
+	tokens    []Token
+}
+
+func (lx *lexer) peek(offset int) rune {
+	if lx.pos+offset < len(lx.src) {
+		return lx.src[lx.pos+offset]
+	}
+	return 0
+}
+
+func (lx *lexer) flushText() {
+	if lx.textStart < lx.pos {
+		lx.tokens = append(lx.tokens, Token{Kind: Text, Text: string(lx.src[lx.textStart:lx.pos])})
+	}
+	lx.textStart = lx.pos
+}
+
+func (lx *lexer) emit(kind TokenKind, end int) {
+	lx.flushText()
+	lx.tokens = append(lx.tokens, Token{Kind: kind, Text: string(lx.src[lx.pos:end])})
+	lx.pos = end
+	lx.textStart = end
+}
+
+func (lx *lexer) next() {
+	r := lx.peek(0)
+	switch {
+	case r == '<' && lx.peek(1) == '#':
+		lx.emit(Comment, lx.findAfter(lx.pos+2, "#>"))
+	case r == '#':
+		lx.emit(Comment, lx.lineEnd(lx.pos))
+	case r == '@' && (lx.peek(1) == '"' || lx.peek(1) == '\'') && lx.atLineEnd(lx.pos+2):
+		lx.emit(HereString, lx.hereStringEnd(lx.peek(1)))
+	case r == '"' || r == '\'':
+		lx.emit(String, lx.stringEnd(lx.pos))
+	case r == '$':
+		lx.emit(Variable, lx.variableEnd())
+	case r == '-' && unicode.IsLetter(lx.peek(1)) && !isIdentifierRune(lx.prev()):
+		end := lx.wordEnd(lx.pos + 1)
+		kind := Parameter
+		if _, ok := powershellOperators[strings.ToLower(string(lx.src[lx.pos+1:end]))]; ok {
+			kind = Operator
+		}
+		lx.emit(kind, end)
+	case unicode.IsDigit(r) && !isIdentifierRune(lx.prev()):
+		lx.emit(Number, lx.numberEnd())
+	case unicode.IsLetter(r) || r == '_':
+		end := lx.wordEnd(lx.pos)
+		word := string(lx.src[lx.pos:end])
+		if _, ok := powershellKeywords[strings.ToLower(word)]; ok {
+			lx.emit(Keyword, end)
+		} else if isCmdletName(word) {
+			lx.emit(Cmdlet, end)
+		} else {
+			lx.pos = end
+		}
+	case strings.ContainsRune("=+-*/%!|><&,;:.", r):
+		end := lx.pos + 1
+		for end < len(lx.src) && strings.ContainsRune("=+-*/%!|><&:.", lx.src[end]) {
+			end++
+		}
+		lx.emit(Operator, end)
+	default:
+		lx.pos++
+	}
+}
+
+func (lx *lexer) prev() rune {
+	if lx.pos > 0 {
+		return lx.src[lx.pos-1]
+	}
+	return 0
+}
+
+func (lx *lexer) lineEnd(from int) int {
+	for from < len(lx.src) && lx.src[from] != '\n' {
+		from++
+	}
+	return from
+}
+
+func (lx *lexer) atLineEnd(from int) bool {
+	for from < len(lx.src) && (lx.src[from] == ' ' || lx.src[from] == '\t' || lx.src[from] == '\r') {
+		from++
+	}
+	return from >= len(lx.src) || lx.src[from] == '\n'
+}
+
+func (lx *lexer) findAfter(from int, terminator string) int {
+	t := []rune(terminator)
+	for i := from; i+len(t) <= len(lx.src); i++ {
+		matched := true
+		for j := range t {
+			if lx.src[i+j] != t[j] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return i + len(t)
+		}
+	}
+	return len(lx.src)
+}
+
+// A here-string ends with the quote and @ at the start of a line.
+func (lx *lexer) hereStringEnd(quote rune) int {
+	i := lx.lineEnd(lx.pos)
+	for i < len(lx.src) {
+		i++
+		if i+1 < len(lx.src) && lx.src[i] == quote && lx.src[i+1] == '@' {
+			return i + 2
+		}
+		i = lx.lineEnd(i)
+	}
+	return len(lx.src)
+}
+
+// stringEnd returns the end of the string starting with the quote at from. The $( ) subexpressions of a double
+// quoted string may hold strings of their own.
+func (lx *lexer) stringEnd(from int) int {
+	quote := lx.src[from]
+	i := from + 1
+	for i < len(lx.src) {
+		switch {
+		case quote == '"' && lx.src[i] == '`':
+			i += 2
+			continue
+		case quote == '"' && lx.src[i] == '$' && i+1 < len(lx.src) && lx.src[i+1] == '(':
+			i = lx.subexpressionEnd(i + 1)
+			continue
+		case lx.src[i] == quote:
+			// doubled quotes are an escaped quote
+			if i+1 < len(lx.src) && lx.src[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1
+		}
+		i++
+	}
+	return len(lx.src)
+}
+
+// subexpressionEnd returns the end of the parentheses opening at from, skipping the strings inside them.
+func (lx *lexer) subexpressionEnd(from int) int {
+	depth := 0
+	for i := from; i < len(lx.src); {
+		switch lx.src[i] {
+		case '"', '\'':
+			i = lx.stringEnd(i)
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+		i++
+	}
+	return len(lx.src)
+}
+
+func (lx *lexer) variableEnd() int {
+	i := lx.pos + 1
+	if i < len(lx.src) && lx.src[i] == '{' {
+		for i < len(lx.src) && lx.src[i] != '}' && lx.src[i] != '\n' {
+			i++
+		}
+		if i < len(lx.src) && lx.src[i] == '}' {
+			i++
+		}
+		return i
+	}
+	if i < len(lx.src) && strings.ContainsRune("$?^_", lx.src[i]) && !isIdentifierRune(lx.peek(2)) {
+		return i + 1
+	}
+	for i < len(lx.src) && (isIdentifierRune(lx.src[i]) && lx.src[i] != '-' || lx.src[i] == ':' && i+1 < len(lx.src) && isIdentifierRune(lx.src[i+1])) {
+		i++
+	}
+	return i
+}
+
+func (lx *lexer) wordEnd(from int) int {
+	for from < len(lx.src) && isIdentifierRune(lx.src[from]) {
+		from++
+	}
+	return from
+}
+
+func (lx *lexer) numberEnd() int {
+	i := lx.pos
+	for i < len(lx.src) && (unicode.IsDigit(lx.src[i]) || unicode.IsLetter(lx.src[i]) || lx.src[i] == '.' && i+1 < len(lx.src) && unicode.IsDigit(lx.src[i+1])) {
+		i++
+	}
+	return i
+}
+
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+}
+
+// isCmdletName reports whether word looks like a Verb-Noun command name.
+func isCmdletName(word string) bool {
+	verb, noun, found := strings.Cut(word, "-")
+	if !found || verb == "" || noun == "" {
+		return false
+	}
+	for _, r := range verb {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package highlight
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenizePowerShell(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// want lists the tokens other than plain text
+		want []Token
+	}{
+		{"comment", "# note\nexit", []Token{{Comment, "# note"}, {Keyword, "exit"}}},
+		{"block comment", "<# one\ntwo #> $x", []Token{{Comment, "<# one\ntwo #>"}, {Variable, "$x"}}},
+		{"unterminated block comment", "<# open\n$x", []Token{{Comment, "<# open\n$x"}}},
+		{"cmdlet and parameters", "Get-ChildItem -Path C:\\ -Recurse", []Token{{Cmdlet, "Get-ChildItem"}, {Parameter, "-Path"}, {Operator, ":"}, {Parameter, "-Recurse"}}},
+		{"comparison operators", "if ($a -eq 1 -and $b -NotMatch 'x') {}", []Token{
+			{Keyword, "if"}, {Variable, "$a"}, {Operator, "-eq"}, {Number, "1"}, {Operator, "-and"}, {Variable, "$b"}, {Operator, "-NotMatch"}, {String, "'x'"},
+		}},
+		{"variables", "$env:PATH + ${my var} + $_.Name + $$", []Token{
+			{Variable, "$env:PATH"}, {Operator, "+"}, {Variable, "${my var}"}, {Operator, "+"}, {Variable, "$_"}, {Operator, "."}, {Operator, "+"}, {Variable, "$$"},
+		}},
+		{"numbers", "$x = 0x1F + 1.5kb", []Token{{Variable, "$x"}, {Operator, "="}, {Number, "0x1F"}, {Operator, "+"}, {Number, "1.5kb"}}},
+		{"doubled quotes", `'it''s' "say ""hi"""`, []Token{{String, `'it''s'`}, {String, `"say ""hi"""`}}},
+		{"backtick escapes", "\"a `\" b\" $x", []Token{{String, "\"a `\" b\""}, {Variable, "$x"}}},
+		{"subexpression", `"now $(Get-Date) ok" $x`, []Token{{String, `"now $(Get-Date) ok"`}, {Variable, "$x"}}},
+		{"nested subexpression with strings", `"a $(Join-Path "x" $("y" + ')')) b" # done`, []Token{
+			{String, `"a $(Join-Path "x" $("y" + ')')) b"`}, {Comment, "# done"},
+		}},
+		{"no subexpression in single quotes", `'$(' "x" ')'`, []Token{{String, `'$('`}, {String, `"x"`}, {String, `')'`}}},
+		{"here-string", "$s = @\"\nline $x\n\"inner\" \"@ not the end\n\"@\n$y", []Token{
+			{Variable, "$s"}, {Operator, "="}, {HereString, "@\"\nline $x\n\"inner\" \"@ not the end\n\"@"}, {Variable, "$y"},
+		}},
+		{"literal here-string", "@'\n'@ $y", []Token{{HereString, "@'\n'@"}, {Variable, "$y"}}},
+		{"here-string needs a line break", "@\"text\"@", []Token{{String, "\"text\""}}},
+		{"unterminated here-string", "@\"\nopen\n$x", []Token{{HereString, "@\"\nopen\n$x"}}},
+		{"keyword case", "FUNCTION Test-It { Return }", []Token{{Keyword, "FUNCTION"}, {Cmdlet, "Test-It"}, {Keyword, "Return"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := TokenizePowerShell(tt.src)
+			var text strings.Builder
+			var got []Token
+			for _, token := range tokens {
+				text.WriteString(token.Text)
+				if token.Kind != Text {
+					got = append(got, token)
+				}
+			}
+			if text.String() != tt.src {
+				t.Errorf("tokens join to %q, want the source %q", text.String(), tt.src)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokens = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package highlight
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/styles"
+)
+
+// styleCodes holds the escape sequences a style wraps text in, so tokens can be coloured without
+// running every token through lipgloss.
+type styleCodes struct {
+	prefix string
+	suffix string
+}
+
+func newStyleCodes(style lipgloss.Style) styleCodes {
+	const marker = "\x00"
+	rendered := style.Render(marker)
+	prefix, suffix, found := strings.Cut(rendered, marker)
+	if !found {
+		return styleCodes{}
+	}
+	return styleCodes{prefix: prefix, suffix: suffix}
+}
+
+// RenderLines renders tokens as coloured lines. Tokens spanning several lines are styled line by line
+// so each line can be displayed on its own.
+func RenderLines(tokens []Token, s styles.SyntaxStyles) []string {
+	codes := map[TokenKind]styleCodes{
+		Keyword:    newStyleCodes(s.Keyword),
+		Variable:   newStyleCodes(s.Variable),
+		String:     newStyleCodes(s.String),
+		HereString: newStyleCodes(s.HereString),
+		Comment:    newStyleCodes(s.Comment),
+		Cmdlet:     newStyleCodes(s.Cmdlet),
+		Operator:   newStyleCodes(s.Operator),
+		Parameter:  newStyleCodes(s.Parameter),
+		Number:     newStyleCodes(s.Number),
+	}
+
+	var lines []string
+	var line strings.Builder
+	for _, token := range tokens {
+		c := codes[token.Kind]
+		parts := strings.Split(token.Text, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, line.String())
+				line.Reset()
+			}
+			if part == "" {
+				continue
+			}
+			line.WriteString(c.prefix)
+			line.WriteString(part)
+			line.WriteString(c.suffix)
+		}
+	}
+	lines = append(lines, line.String())
+	return lines
+}
+
+// Mark lays a style over the text between the byte offsets Start and End of a line, counted without escape sequences.
+type Mark struct {
+	Start int
+	End   int
+	Style lipgloss.Style
+}
+
+// Overlay applies marks, sorted and not overlapping, to a line rendered by RenderLines. The token colours are kept
+// outside the marks and, where the mark style does not replace them, inside.
+func Overlay(line string, marks []Mark) string {
+	var b strings.Builder
+	active := ""
+	plain := 0
+	var current styleCodes
+	inMark := false
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' {
+			// a CSI sequence runs up to its final byte, other escapes are two bytes long
+			end := i + 1
+			if end < len(line) && line[end] == '[' {
+				end++
+				for end < len(line) && (line[end] < '@' || line[end] > '~') {
+					end++
+				}
+			}
+			end = min(end+1, len(line))
+			sequence := line[i:end]
+			b.WriteString(sequence)
+			if sequence == "\x1b[0m" || sequence == "\x1b[m" {
+				active = ""
+				if inMark {
+					// the token reset the mark too
+					b.WriteString(current.prefix)
+				}
+			} else {
+				active += sequence
+			}
+			i = end
+			continue
+		}
+		if !inMark && len(marks) > 0 && plain == marks[0].Start {
+			current = newStyleCodes(marks[0].Style)
+			b.WriteString(current.prefix)
+			inMark = true
+		}
+		b.WriteByte(line[i])
+		i++
+		plain++
+		if inMark && plain == marks[0].End {
+			b.WriteString(current.suffix)
+			b.WriteString(active)
+			marks = marks[1:]
+			inMark = false
+		}
+	}
+	if inMark {
+		b.WriteString(current.suffix)
+	}
+	return b.String()
+}
+
+// PowerShell returns the highlighted lines of PowerShell source.
+func PowerShell(src string) []string {
+	return RenderLines(TokenizePowerShell(src), styles.SyntaxStyle)
+}
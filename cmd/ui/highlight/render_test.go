@@ -0,0 +1,90 @@
+package highlight
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/styles"
+)
+
+var escape = regexp.MustCompile("^\x1b\\[[0-9;]*m")
+
+// styledByte is a byte of a rendered line with the token colour and whether a mark covers it.
+type styledByte struct {
+	char   byte
+	colour string
+	marked bool
+}
+
+// decode follows the escape sequences of a rendered line, markPrefix starts a mark and a reset ends everything.
+func decode(line string, markPrefix string) []styledByte {
+	var decoded []styledByte
+	colour, marked := "", false
+	for i := 0; i < len(line); {
+		if sequence := escape.FindString(line[i:]); sequence != "" {
+			switch sequence {
+			case "\x1b[0m":
+				colour, marked = "", false
+			case markPrefix:
+				marked = true
+			default:
+				colour += sequence
+			}
+			i += len(sequence)
+			continue
+		}
+		decoded = append(decoded, styledByte{char: line[i], colour: colour, marked: marked})
+		i++
+	}
+	return decoded
+}
+
+func TestOverlay(t *testing.T) {
+	previous := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(previous) })
+	mark := lipgloss.NewStyle().Background(lipgloss.Color("3"))
+	markPrefix := newStyleCodes(mark).prefix
+	if markPrefix == "" || newStyleCodes(styles.SyntaxStyle.Variable).prefix == "" {
+		t.Fatal("styles render without colours")
+	}
+
+	tests := []struct {
+		name   string
+		src    string
+		marks  []Mark
+		marked string
+	}{
+		{"plain text", "plain words", []Mark{{Start: 6, End: 11, Style: mark}}, "words"},
+		{"inside a token", "$name = 1", []Mark{{Start: 1, End: 3, Style: mark}}, "na"},
+		{"across tokens", "Write-Host $name", []Mark{{Start: 6, End: 13, Style: mark}}, "Host $n"},
+		{"several marks", "$a $b $c", []Mark{{Start: 0, End: 2, Style: mark}, {Start: 6, End: 8, Style: mark}}, "$a$c"},
+		{"non-ASCII", "'İstanbul' $x", []Mark{{Start: 1, End: 4, Style: mark}}, "İs"},
+		{"whole line", "# note", []Mark{{Start: 0, End: 6, Style: mark}}, "# note"},
+		{"no marks", "Get-Item $x", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := RenderLines(TokenizePowerShell(tt.src), styles.SyntaxStyle)[0]
+			before := decode(line, markPrefix)
+			after := decode(Overlay(line, tt.marks), markPrefix)
+			if len(after) != len(before) {
+				t.Fatalf("overlay has %d characters, want %d", len(after), len(before))
+			}
+			var marked []byte
+			for i := range after {
+				if after[i].char != before[i].char || after[i].colour != before[i].colour {
+					t.Errorf("byte %d = %q in %q, want %q in %q", i, after[i].char, after[i].colour, before[i].char, before[i].colour)
+				}
+				if after[i].marked {
+					marked = append(marked, after[i].char)
+				}
+			}
+			if string(marked) != tt.marked {
+				t.Errorf("marked %q, want %q", marked, tt.marked)
+			}
+		})
+	}
+}
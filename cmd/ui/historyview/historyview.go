@@ -0,0 +1,126 @@
+package historyview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/common"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/styles"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
+)
+
+type historyItem struct {
+	entry utils.HistoryEntry
+}
+
+func (i historyItem) Title() string { return i.entry.Summary() }
+func (i historyItem) Description() string {
+	if changed := i.entry.ChangedProfiles(); len(changed) > 0 {
+		return fmt.Sprintf("%s, %d profile(s) changed since", i.entry.Outcome(), len(changed))
+	}
+	return i.entry.Outcome()
+}
+func (i historyItem) FilterValue() string { return strings.Join(i.entry.Profiles, " ") }
+
+type model struct {
+	historyList list.Model
+	viewChanger view.ViewChanger
+	windowSize  tea.WindowSizeMsg
+	deps        view.Dependencies
+}
+
+func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg, deps view.Dependencies) *model {
+	l.Logger.Debug("Initializing launch history")
+	entries, err := utils.LoadHistory()
+	if err != nil {
+		l.Logger.Error("Failed to load history", "error", err)
+	}
+	items := make([]list.Item, len(entries))
+	for i, entry := range entries {
+		items[i] = historyItem{entry: entry}
+	}
+
+	historyList := list.New(items, list.NewDefaultDelegate(), windowSize.Width, windowSize.Height)
+	historyList.Title = "Recent Launches"
+	historyList.Styles.Title = styles.TitleStyle
+	historyList.Styles.HelpStyle = styles.HelpStyle
+	historyList.SetStatusBarItemName("launch", "launches")
+	historyList.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "relaunch"))}
+	}
+
+	return &model{
+		historyList: historyList,
+		viewChanger: viewChanger,
+		windowSize:  windowSize,
+		deps:        deps,
+	}
+}
+
+func (m *model) Init() tea.Cmd {
+	return tea.SetWindowTitle("Recent Launches")
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.windowSize = msg
+		m.historyList.SetSize(msg.Width, msg.Height)
+	case common.InlineLaunchFinishedMsg:
+		status := fmt.Sprintf("%s %s", msg.Shell, msg.Result)
+		if msg.Err != nil && msg.Result.PID == 0 {
+			status = fmt.Sprintf("%s failed to start: %v", msg.Shell, msg.Err)
+		}
+		return m, m.historyList.NewStatusMessage(styles.StatusMessageStyle(status))
+	case common.LaunchFinishedMsg:
+		if msg.Err != nil {
+			l.Logger.Error("Failed to relaunch", "shell", msg.Shell, "error", msg.Err)
+		}
+		return m, m.historyList.NewStatusMessage(styles.StatusMessageStyle(msg.Status()))
+	case tea.KeyMsg:
+		if m.historyList.FilterState() == list.Filtering {
+			break
+		}
+		if msg.String() == "enter" {
+			item, ok := m.historyList.SelectedItem().(historyItem)
+			if !ok {
+				break
+			}
+			return m, m.relaunch(item.entry)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.historyList, cmd = m.historyList.Update(msg)
+	return m, cmd
+}
+
+func (m *model) relaunch(entry utils.HistoryEntry) tea.Cmd {
+	l.Logger.Info("Relaunching from history", "shell", entry.Shell, "profiles", entry.Profiles)
+	req, warnings, err := utils.RelaunchRequest(entry)
+	if err != nil {
+		l.Logger.Error("Failed to relaunch", "error", err)
+		return m.historyList.NewStatusMessage(styles.StatusMessageStyle("Relaunch failed: " + err.Error()))
+	}
+	if req.Mode == launcher.ModeInline {
+		return common.LaunchInline(m.deps.Launcher, req, func(result launcher.Result, err error) tea.Msg {
+			return common.InlineLaunchFinishedMsg{Shell: req.Shell, Result: result, Err: err}
+		})
+	}
+	return tea.Batch(common.Launch(m.deps.Launcher, req, req.Shell, warnings), m.historyList.NewStatusMessage(styles.StatusMessageStyle("Relaunching "+req.Shell)))
+}
+
+func (m *model) View() string {
+	return m.historyList.View()
+}
+
+// FilterState lets the main view pass keys through while the history is being filtered.
+func (m *model) FilterState() list.FilterState {
+	return m.historyList.FilterState()
+}
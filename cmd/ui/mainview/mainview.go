@@ -12,14 +12,16 @@ type mainModel struct {
 	currentView   tea.Model
 	previousViews []tea.Model
 	windowSize    tea.WindowSizeMsg
+	deps          view.Dependencies
 }
 
-func NewMainModel(windowsSize tea.WindowSizeMsg) *mainModel {
+func NewMainModel(windowsSize tea.WindowSizeMsg, deps view.Dependencies) *mainModel {
 	l.Logger.Info("Creating a new main view")
 	mainModel := &mainModel{
 		windowSize: windowsSize,
+		deps:       deps,
 	}
-	mainView := menuview.New(mainModel, windowsSize)
+	mainView := menuview.New(mainModel, windowsSize, deps)
 	mainModel.currentView = mainView
 	return mainModel
 }
@@ -44,16 +46,17 @@ func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "ctrl+left":
 			if len(m.previousViews) > 0 {
-				previousView := m.previousViews[len(m.previousViews)-1]
-				m.previousViews = m.previousViews[:len(m.previousViews)-1]
-				l.Logger.Debug("Navigating back to previous view", "stackSize", len(m.previousViews))
-				m.ClearSelectedItems()
-				m.currentView = previousView
+				m.back()
 				return m, nil
 			}
 		}
 	case ChangeViewMsg:
 		return m.handleChangeViewMsg(msg)
+	case backMsg:
+		if len(m.previousViews) > 0 {
+			m.back()
+		}
+		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -89,6 +92,22 @@ func (m *mainModel) ChangeView(newView tea.Model, clearSelections bool) tea.Cmd
 	}
 }
 
+type backMsg struct{}
+
+func (m *mainModel) Back() tea.Cmd {
+	return func() tea.Msg {
+		return backMsg{}
+	}
+}
+
+func (m *mainModel) back() {
+	previousView := m.previousViews[len(m.previousViews)-1]
+	m.previousViews = m.previousViews[:len(m.previousViews)-1]
+	l.Logger.Debug("Navigating back to previous view", "stackSize", len(m.previousViews))
+	m.ClearSelectedItems()
+	m.currentView = previousView
+}
+
 func (m *mainModel) ClearSelectedItems() {
 	if selectable, ok := m.currentView.(view.Clearable); ok {
 		selectable.ClearSelectedItems()
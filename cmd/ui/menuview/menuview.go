@@ -5,7 +5,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/historyview"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/profileselector"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/setsview"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/shortcutview"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/styles"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
@@ -26,13 +28,16 @@ type model struct {
 	menuList    list.Model
 	viewChanger view.ViewChanger
 	windowSize  tea.WindowSizeMsg
+	deps        view.Dependencies
 }
 
-func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg) *model {
+func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg, deps view.Dependencies) *model {
 	l.Logger.Debug("Initializing main menu")
 	items := []list.Item{
 		menuItem{title: "Select Profiles", description: "PowerShell profile selection screen.", pageName: "profilesView"},
 		menuItem{title: "Create Shortcuts", description: "Shortcut creation screen.", pageName: "shortcutsView"},
+		menuItem{title: "Profile Sets", description: "Launch and manage named sets of profiles.", pageName: "setsView"},
+		menuItem{title: "Recent", description: "Relaunch a recent shell and profile combination.", pageName: "historyView"},
 		menuItem{title: "Exit", description: "Exit the application.", pageName: "exit"},
 	}
 
@@ -60,6 +65,7 @@ func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg) *model {
 		menuList:    list,
 		viewChanger: viewChanger,
 		windowSize:  windowSize,
+		deps:        deps,
 	}
 }
 
@@ -80,10 +86,16 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch item.PageName() {
 			case "profilesView":
 				l.Logger.Debug("Changing view to profile selector")
-				return m, m.viewChanger.ChangeView(profileselector.New(m.viewChanger, m.windowSize), true)
+				return m, m.viewChanger.ChangeView(profileselector.New(m.viewChanger, m.windowSize, m.deps), true)
 			case "shortcutsView":
 				l.Logger.Debug("Changing view to shortcut selector")
-				return m, m.viewChanger.ChangeView(shortcutview.New(m.viewChanger, m.windowSize), true)
+				return m, m.viewChanger.ChangeView(shortcutview.New(m.viewChanger, m.windowSize, m.deps), true)
+			case "setsView":
+				l.Logger.Debug("Changing view to profile sets")
+				return m, m.viewChanger.ChangeView(setsview.New(m.viewChanger, m.windowSize, m.deps), true)
+			case "historyView":
+				l.Logger.Debug("Changing view to launch history")
+				return m, m.viewChanger.ChangeView(historyview.New(m.viewChanger, m.windowSize, m.deps), true)
 			case "exit":
 				l.Logger.Info("Exiting application")
 				return m, tea.Quit
@@ -1,11 +1,16 @@
 package profileselector
 
 import (
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/codeviewerview"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/common"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/diffview"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/setformview"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/shellview"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/styles"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
@@ -17,9 +22,10 @@ type model struct {
 	selected     map[int]struct{}
 	windowSize   tea.WindowSizeMsg
 	viewChanger  view.ViewChanger
+	deps         view.Dependencies
 }
 
-func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg) *model {
+func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg, deps view.Dependencies) *model {
 	l.Logger.Debug("Initializing profile list")
 	loadConfig, err := utils.LoadConfig()
 	if err != nil {
@@ -34,16 +40,9 @@ func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg) *model {
 	}
 
 	var items []list.Item
+	// the loaded profiles are listed as they are, the shell view needs their headers
 	for _, p := range profiles {
-		item := types.ProfileItem{
-			ItemTitle:       p.ItemTitle,
-			ItemDescription: p.ItemDescription,
-			IsValid:         p.IsValid,
-			Path:            p.Path,
-			Shell:           p.Shell,
-			Name:            p.Name,
-		}
-		items = append(items, item)
+		items = append(items, p)
 	}
 	delegateKeyMap, err := styles.NewProfileDelegateKeyMap()
 	if err != nil {
@@ -71,6 +70,7 @@ func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg) *model {
 		selected:     make(map[int]struct{}),
 		viewChanger:  viewChanger,
 		windowSize:   windowSize,
+		deps:         deps,
 	}
 }
 
@@ -84,6 +84,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.windowSize = msg
 		m.profilesList.SetSize(msg.Width, msg.Height)
+	case common.SetSavedMsg:
+		return m, m.profilesList.NewStatusMessage(styles.StatusMessageStyle(msg.Status()))
 	case tea.KeyMsg:
 		switch msg.String() {
 		case " ":
@@ -119,24 +121,13 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "enter":
 			// load selected profiles
-			var selectedProfiles []types.ProfileItem
-			if len(m.selected) == 0 {
-				l.Logger.Warn("No Profiles selected, using currently highlighted profile")
-				i := m.profilesList.Index()
-				if i < 0 || i >= len(m.profilesList.Items()) {
-					l.Logger.Error("Invalid index", "index", i)
-					break
-				}
-				item := m.profilesList.Items()[i].(types.ProfileItem)
-				selectedProfiles = append(selectedProfiles, item)
-			}
-			for i := range m.selected {
-				item := m.profilesList.Items()[i].(types.ProfileItem)
-				selectedProfiles = append(selectedProfiles, item)
+			selectedProfiles := m.selectedProfiles()
+			if len(selectedProfiles) == 0 {
+				break
 			}
 			// open shellview with profiles selected
 			l.Logger.Info("Selected profiles", "profiles", selectedProfiles)
-			return m, m.viewChanger.ChangeView(shellview.New(selectedProfiles, m.windowSize, m.viewChanger, false), true)
+			return m, m.viewChanger.ChangeView(shellview.New(selectedProfiles, m.windowSize, m.viewChanger, false, m.deps), true)
 		case "v":
 			// view profile content
 			i := m.profilesList.Index()
@@ -145,7 +136,60 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 			item := m.profilesList.Items()[i].(types.ProfileItem)
+			if line := item.FirstIssueLine(); line > 0 {
+				return m, m.viewChanger.ChangeView(codeviewerview.NewAtLine(item.Path, line, m.windowSize, m.viewChanger), true)
+			}
 			return m, m.viewChanger.ChangeView(codeviewerview.New(item.Path, m.windowSize, m.viewChanger), true)
+		case "p":
+			// preview the merged script for the selection
+			if m.profilesList.FilterState() == list.Filtering {
+				break
+			}
+			selectedProfiles := m.selectedProfiles()
+			if len(selectedProfiles) == 0 {
+				break
+			}
+			shell, err := utils.FindShellForProfile(selectedProfiles[0])
+			if err != nil {
+				l.Logger.Error("Failed to find shell for preview", "error", err)
+				return m, m.profilesList.NewStatusMessage(styles.StatusMessageStyle(err.Error()))
+			}
+			profilePaths := utils.ProfilesForShell(selectedProfiles, shell)
+			env, _ := utils.MergeProfileEnv(profilePaths)
+			workDir, _ := utils.MergeProfileWorkDir(profilePaths)
+			args, _ := utils.ResolveArgs(shell, nil, profilePaths)
+			req := utils.NewLaunchRequest(shell, profilePaths, env, workDir, args)
+			title := fmt.Sprintf("Preview (%s): %d profile(s)", shell.Name, len(profilePaths))
+			return m, m.viewChanger.ChangeView(codeviewerview.NewFromContent(title, req.Script, req.Adapter().Kind(), m.windowSize, m.viewChanger), false)
+		case "a":
+			// save the selection as a new profile set
+			if m.profilesList.FilterState() == list.Filtering {
+				break
+			}
+			selectedProfiles := m.selectedProfiles()
+			if len(selectedProfiles) == 0 {
+				break
+			}
+			shell, err := utils.FindShellForProfile(selectedProfiles[0])
+			if err != nil {
+				l.Logger.Error("Failed to find shell for set", "error", err)
+				return m, m.profilesList.NewStatusMessage(styles.StatusMessageStyle(err.Error()))
+			}
+			set := utils.Set{Shell: shell.GetShortName(), Profiles: utils.ProfilesForShell(selectedProfiles, shell)}
+			return m, m.viewChanger.ChangeView(setformview.New(m.viewChanger, m.windowSize, set, ""), false)
+		case "d":
+			// diff the two selected profiles
+			if m.profilesList.FilterState() == list.Filtering {
+				break
+			}
+			if len(m.selected) != 2 {
+				l.Logger.Warn("Diff requires two selected profiles", "selected", len(m.selected))
+				return m, m.profilesList.NewStatusMessage(styles.StatusMessageStyle("Select two profiles to compare"))
+			}
+			indexes := common.SortedSelection(m.selected)
+			left := m.profilesList.Items()[indexes[0]].(types.ProfileItem)
+			right := m.profilesList.Items()[indexes[1]].(types.ProfileItem)
+			return m, m.viewChanger.ChangeView(diffview.New(left.Path, right.Path, m.windowSize, m.viewChanger), false)
 		}
 	}
 
@@ -157,6 +201,26 @@ func (m *model) View() string {
 	return m.profilesList.View()
 }
 
+// selectedProfiles returns the selected profiles in list order, or the highlighted profile when nothing is selected.
+func (m *model) selectedProfiles() []types.ProfileItem {
+	var selectedProfiles []types.ProfileItem
+	if len(m.selected) == 0 {
+		l.Logger.Warn("No Profiles selected, using currently highlighted profile")
+		i := m.profilesList.Index()
+		if i < 0 || i >= len(m.profilesList.Items()) {
+			l.Logger.Error("Invalid index", "index", i)
+			return nil
+		}
+		item := m.profilesList.Items()[i].(types.ProfileItem)
+		selectedProfiles = append(selectedProfiles, item)
+	}
+	for _, i := range common.SortedSelection(m.selected) {
+		item := m.profilesList.Items()[i].(types.ProfileItem)
+		selectedProfiles = append(selectedProfiles, item)
+	}
+	return selectedProfiles
+}
+
 func (m *model) ClearSelectedItems() {
 	m.selected = make(map[int]struct{})
 }
@@ -0,0 +1,55 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/shellview"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
+)
+
+type noViewChanger struct{}
+
+func (noViewChanger) ChangeView(tea.Model, bool) tea.Cmd { return nil }
+func (noViewChanger) Back() tea.Cmd                      { return nil }
+
+// writeFile writes content to the path under dir and returns the path.
+func writeFile(t *testing.T, dir string, name string, content string, perm os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSelectedProfilesKeepShellVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test shell is a shell script")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	shell := writeFile(t, dir, "testbash", "#!/bin/sh\necho 5.2.0\n", 0o755)
+	profiles := filepath.Join(dir, "profiles")
+	writeFile(t, profiles, "New.Profile.sh", "### SHELL:testbash:SHELL ###\n### DESCRIPTION:Needs a new shell:DESCRIPTION ###\n### SHELLVERSION:>=9:SHELLVERSION ###\necho loaded\n", 0o644)
+	writeFile(t, dir, "config/GoPowerShellLauncher/config.yaml", "profile:\n  path: "+profiles+"\nshells:\n  - name: testbash\n    path: "+shell+"\n    kind: bash\n    short_names: [testbash]\n", 0o644)
+
+	size := tea.WindowSizeMsg{Width: 300, Height: 40}
+	m := New(noViewChanger{}, size, view.Dependencies{})
+	selected := m.selectedProfiles()
+	if len(selected) != 1 || selected[0].ShellVersion != ">=9" {
+		t.Fatalf("selected profiles = %+v, want the profile with its SHELLVERSION", selected)
+	}
+	shells := shellview.New(selected, size, noViewChanger{}, false, view.Dependencies{})
+	if got := shells.View(); !strings.Contains(got, "incompatible") || !strings.Contains(got, "needs testbash >=9") {
+		t.Errorf("shell view does not flag the incompatible shell:\n%s", got)
+	}
+}
@@ -0,0 +1,229 @@
+package setformview
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/common"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/styles"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
+)
+
+const (
+	nameInput = iota
+	shellInput
+	profilesInput
+	workDirInput
+	argsInput
+	inlineInput
+)
+
+var (
+	focusedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	blurredStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	noStyle      = lipgloss.NewStyle()
+	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	focusedButton = focusedStyle.Render("[ Save ]")
+	blurredButton = fmt.Sprintf("[ %s ]", blurredStyle.Render("Save"))
+)
+
+type model struct {
+	focusIndex   int
+	inputs       []textinput.Model
+	previousName string
+	original     utils.Set
+	err          error
+	windowSize   tea.WindowSizeMsg
+	viewChanger  view.ViewChanger
+}
+
+// New edits the set, previousName is the name of the set being edited and empty for a new set.
+func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg, set utils.Set, previousName string) *model {
+	l.Logger.Debug("Initializing set form", "set", set.Name, "previousName", previousName)
+	m := &model{
+		inputs:       make([]textinput.Model, 6),
+		previousName: previousName,
+		original:     set,
+		windowSize:   windowSize,
+		viewChanger:  viewChanger,
+	}
+	inline := "n"
+	if set.Inline {
+		inline = "y"
+	}
+	for i := range m.inputs {
+		t := textinput.New()
+		t.Cursor.Style = focusedStyle
+		t.Width = max(20, windowSize.Width-24)
+		switch i {
+		case nameInput:
+			t.Prompt = "Name: "
+			t.Placeholder = "Name of the set"
+			t.CharLimit = 64
+			t.SetValue(set.Name)
+		case shellInput:
+			t.Prompt = "Shell: "
+			t.Placeholder = "pwsh"
+			t.CharLimit = 32
+			t.SetValue(set.Shell)
+		case profilesInput:
+			t.Prompt = "Profiles: "
+			t.Placeholder = "Comma separated profile paths, in launch order"
+			t.SetValue(strings.Join(set.Profiles, ","))
+		case workDirInput:
+			t.Prompt = "Working directory: "
+			t.Placeholder = "Optional, overrides the profile WORKDIR headers"
+			t.SetValue(set.WorkDir)
+		case argsInput:
+			t.Prompt = "Shell arguments: "
+			t.Placeholder = "Optional, replaces the shell and profile ARGS arguments"
+			t.SetValue(utils.JoinArgs(set.Args))
+		case inlineInput:
+			t.Prompt = "Inline (y/n): "
+			t.CharLimit = 1
+			t.SetValue(inline)
+		}
+		m.inputs[i] = t
+	}
+	m.focus(0)
+	return m
+}
+
+func (m *model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.windowSize = msg
+		for i := range m.inputs {
+			m.inputs[i].Width = max(20, msg.Width-24)
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return m, m.viewChanger.Back()
+		case "enter", "tab", "shift+tab", "up", "down":
+			s := msg.String()
+			if s == "enter" && m.focusIndex == len(m.inputs) {
+				return m, m.save()
+			}
+			if s == "up" || s == "shift+tab" {
+				m.focusIndex--
+			} else {
+				m.focusIndex++
+			}
+			if m.focusIndex > len(m.inputs) {
+				m.focusIndex = 0
+			} else if m.focusIndex < 0 {
+				m.focusIndex = len(m.inputs)
+			}
+			return m, m.focus(m.focusIndex)
+		}
+	}
+
+	cmds := make([]tea.Cmd, len(m.inputs))
+	for i := range m.inputs {
+		m.inputs[i], cmds[i] = m.inputs[i].Update(msg)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *model) focus(index int) tea.Cmd {
+	m.focusIndex = index
+	var cmd tea.Cmd
+	for i := range m.inputs {
+		if i == index {
+			cmd = m.inputs[i].Focus()
+			m.inputs[i].PromptStyle = focusedStyle
+			m.inputs[i].TextStyle = focusedStyle
+			continue
+		}
+		m.inputs[i].Blur()
+		m.inputs[i].PromptStyle = noStyle
+		m.inputs[i].TextStyle = noStyle
+	}
+	return cmd
+}
+
+func (m *model) save() tea.Cmd {
+	// fields without an input, such as the export settings, are kept
+	set := m.original
+	set.Name = strings.TrimSpace(m.inputs[nameInput].Value())
+	set.Shell = strings.TrimSpace(m.inputs[shellInput].Value())
+	set.WorkDir = strings.TrimSpace(m.inputs[workDirInput].Value())
+	set.Inline = strings.EqualFold(strings.TrimSpace(m.inputs[inlineInput].Value()), "y")
+	set.Args = nil
+	set.Profiles = nil
+	if argsValue := strings.TrimSpace(m.inputs[argsInput].Value()); argsValue != "" {
+		args, err := utils.SplitArgs(argsValue)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		set.Args = args
+	}
+	for _, profile := range utils.SplitProfiles(m.inputs[profilesInput].Value()) {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			set.Profiles = append(set.Profiles, profile)
+		}
+	}
+	l.Logger.Info("Saving set", "set", set, "previousName", m.previousName)
+	saved := common.SetSavedMsg{Name: set.Name}
+	if err := utils.SaveSet(set, m.previousName); errors.Is(err, utils.ErrShortcutsOutdated) {
+		saved.Warning = err.Error()
+	} else if err != nil {
+		l.Logger.Error("Failed to save set", "error", err)
+		m.err = err
+		return nil
+	}
+	return tea.Sequence(m.viewChanger.Back(), func() tea.Msg {
+		return saved
+	})
+}
+
+func (m *model) View() string {
+	var b strings.Builder
+	title := "New Profile Set"
+	if m.previousName != "" {
+		title = "Edit Profile Set: " + m.previousName
+	}
+	b.WriteString(styles.TitleStyle.Render(title))
+	b.WriteString("\n\n")
+	for i := range m.inputs {
+		b.WriteString(m.inputs[i].View())
+		b.WriteRune('\n')
+	}
+	button := blurredButton
+	if m.focusIndex == len(m.inputs) {
+		button = focusedButton
+	}
+	fmt.Fprintf(&b, "\n%s\n", button)
+	if m.err != nil {
+		fmt.Fprintf(&b, "\n%s\n", errorStyle.Render(m.err.Error()))
+	}
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render("tab/↓: next field, shift+tab/↑: previous field, enter on Save: save, esc: cancel"))
+	return b.String()
+}
+
+// FilterState reports the form as filtering so keys like q are typed into the fields.
+func (m *model) FilterState() list.FilterState {
+	return list.Filtering
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,246 @@
+package setsview
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/codeviewerview"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/common"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/setformview"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/styles"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
+)
+
+type setItem struct {
+	set utils.Set
+}
+
+func (i setItem) Title() string { return i.set.Name }
+func (i setItem) Description() string {
+	names := make([]string, len(i.set.Profiles))
+	for j, profile := range i.set.Profiles {
+		names[j] = filepath.Base(profile)
+	}
+	description := fmt.Sprintf("%s: %s", i.set.Shell, strings.Join(names, ", "))
+	if i.set.WorkDir != "" {
+		description += ", workdir: " + i.set.WorkDir
+	}
+	if i.set.Inline {
+		description += ", inline"
+	}
+	return description
+}
+func (i setItem) FilterValue() string { return i.set.Name }
+
+type model struct {
+	setsList      list.Model
+	renaming      bool
+	renameInput   textinput.Model
+	confirmDelete string
+	viewChanger   view.ViewChanger
+	windowSize    tea.WindowSizeMsg
+	deps          view.Dependencies
+}
+
+func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg, deps view.Dependencies) *model {
+	l.Logger.Debug("Initializing profile sets")
+	setsList := list.New(nil, list.NewDefaultDelegate(), windowSize.Width, windowSize.Height-1)
+	setsList.Title = "Profile Sets"
+	setsList.Styles.Title = styles.TitleStyle
+	setsList.Styles.HelpStyle = styles.HelpStyle
+	setsList.SetStatusBarItemName("set", "sets")
+	setsList.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "launch")),
+			key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new")),
+			key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rename")),
+			key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "delete")),
+			key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "preview")),
+		}
+	}
+	renameInput := textinput.New()
+	renameInput.Prompt = "New name: "
+	renameInput.CharLimit = 64
+
+	m := &model{
+		setsList:    setsList,
+		renameInput: renameInput,
+		viewChanger: viewChanger,
+		windowSize:  windowSize,
+		deps:        deps,
+	}
+	m.reload()
+	return m
+}
+
+func (m *model) reload() {
+	sets, err := utils.LoadSets()
+	if err != nil {
+		l.Logger.Error("Failed to load sets", "error", err)
+	}
+	items := make([]list.Item, len(sets))
+	for i, set := range sets {
+		items[i] = setItem{set: set}
+	}
+	m.setsList.SetItems(items)
+}
+
+func (m *model) Init() tea.Cmd {
+	return tea.SetWindowTitle("Profile Sets")
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.windowSize = msg
+		m.setsList.SetSize(msg.Width, msg.Height-1)
+	case common.SetSavedMsg:
+		m.reload()
+		return m, m.status(msg.Status())
+	case common.InlineLaunchFinishedMsg:
+		status := fmt.Sprintf("%s %s", msg.Shell, msg.Result)
+		if msg.Err != nil && msg.Result.PID == 0 {
+			status = fmt.Sprintf("%s failed to start: %v", msg.Shell, msg.Err)
+		}
+		return m, m.status(status)
+	case common.LaunchFinishedMsg:
+		if msg.Err != nil {
+			l.Logger.Error("Failed to launch set", "set", msg.Shell, "error", msg.Err)
+		}
+		return m, m.status(msg.Status())
+	case tea.KeyMsg:
+		if m.renaming {
+			return m.updateRename(msg)
+		}
+		if m.setsList.FilterState() == list.Filtering {
+			break
+		}
+		item, selected := m.setsList.SelectedItem().(setItem)
+		if msg.String() != "x" {
+			m.confirmDelete = ""
+		}
+		switch msg.String() {
+		case "enter":
+			if selected {
+				return m, m.launch(item.set)
+			}
+		case "n":
+			return m, m.viewChanger.ChangeView(setformview.New(m.viewChanger, m.windowSize, utils.Set{}, ""), false)
+		case "e":
+			if selected {
+				return m, m.viewChanger.ChangeView(setformview.New(m.viewChanger, m.windowSize, item.set, item.set.Name), false)
+			}
+		case "r":
+			if selected {
+				m.renaming = true
+				m.renameInput.SetValue(item.set.Name)
+				m.renameInput.CursorEnd()
+				return m, m.renameInput.Focus()
+			}
+		case "x":
+			if !selected {
+				break
+			}
+			if !strings.EqualFold(m.confirmDelete, item.set.Name) {
+				m.confirmDelete = item.set.Name
+				return m, m.status(fmt.Sprintf("Press x again to delete %s", item.set.Name))
+			}
+			m.confirmDelete = ""
+			if err := utils.DeleteSet(item.set.Name); err != nil {
+				l.Logger.Error("Failed to delete set", "error", err)
+				return m, m.status("Delete failed: " + err.Error())
+			}
+			m.reload()
+			return m, m.status("Deleted set " + item.set.Name)
+		case "p":
+			if !selected {
+				break
+			}
+			req, _, err := utils.SetLaunchRequest(item.set)
+			if err != nil {
+				return m, m.status(err.Error())
+			}
+			title := fmt.Sprintf("Preview (%s): %d profile(s)", item.set.Name, len(item.set.Profiles))
+			return m, m.viewChanger.ChangeView(codeviewerview.NewFromContent(title, req.Script, req.Adapter().Kind(), m.windowSize, m.viewChanger), false)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.setsList, cmd = m.setsList.Update(msg)
+	return m, cmd
+}
+
+func (m *model) updateRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.renaming = false
+		m.renameInput.Blur()
+		return m, nil
+	case "enter":
+		m.renaming = false
+		m.renameInput.Blur()
+		item, ok := m.setsList.SelectedItem().(setItem)
+		if !ok {
+			return m, nil
+		}
+		renamed := item.set
+		renamed.Name = strings.TrimSpace(m.renameInput.Value())
+		status := fmt.Sprintf("Renamed %s to %s", item.set.Name, renamed.Name)
+		if err := utils.SaveSet(renamed, item.set.Name); errors.Is(err, utils.ErrShortcutsOutdated) {
+			status += "; Warning: " + err.Error()
+		} else if err != nil {
+			l.Logger.Error("Failed to rename set", "error", err)
+			return m, m.status("Rename failed: " + err.Error())
+		}
+		m.reload()
+		return m, m.status(status)
+	}
+	var cmd tea.Cmd
+	m.renameInput, cmd = m.renameInput.Update(msg)
+	return m, cmd
+}
+
+func (m *model) launch(set utils.Set) tea.Cmd {
+	l.Logger.Info("Launching set", "set", set.Name)
+	req, warnings, err := utils.SetLaunchRequest(set)
+	if err != nil {
+		l.Logger.Error("Failed to prepare set", "error", err)
+		return m.status("Launch failed: " + err.Error())
+	}
+	if req.Mode == launcher.ModeInline {
+		return common.LaunchInline(m.deps.Launcher, req, func(result launcher.Result, err error) tea.Msg {
+			return common.InlineLaunchFinishedMsg{Shell: set.Name, Result: result, Err: err}
+		})
+	}
+	return tea.Batch(common.Launch(m.deps.Launcher, req, set.Name, warnings), m.status("Launching "+set.Name))
+}
+
+func (m *model) status(status string) tea.Cmd {
+	return m.setsList.NewStatusMessage(styles.StatusMessageStyle(status))
+}
+
+func (m *model) View() string {
+	if m.renaming {
+		return lipgloss.JoinVertical(lipgloss.Left, m.setsList.View(), m.renameInput.View())
+	}
+	return m.setsList.View()
+}
+
+// FilterState reports renaming as filtering so keys are typed into the prompt.
+func (m *model) FilterState() list.FilterState {
+	if m.renaming {
+		return list.Filtering
+	}
+	return m.setsList.FilterState()
+}
@@ -0,0 +1,85 @@
+package setsview
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/common"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
+)
+
+type noViewChanger struct{}
+
+func (noViewChanger) ChangeView(tea.Model, bool) tea.Cmd { return nil }
+func (noViewChanger) Back() tea.Cmd                      { return nil }
+
+// launchFinished runs the commands in the background and returns the launch result they send.
+func launchFinished(t *testing.T, cmd tea.Cmd) common.LaunchFinishedMsg {
+	t.Helper()
+	found := make(chan common.LaunchFinishedMsg, 1)
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		switch msg := cmd().(type) {
+		case tea.BatchMsg:
+			for _, cmd := range msg {
+				go run(cmd)
+			}
+		case common.LaunchFinishedMsg:
+			found <- msg
+		}
+	}
+	go run(cmd)
+	select {
+	case msg := <-found:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no launch result")
+	}
+	return common.LaunchFinishedMsg{}
+}
+
+func TestLaunchRunsInTheBackground(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test shell is a shell script")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	shell := filepath.Join(dir, "testbash")
+	profile := filepath.Join(dir, "Dev.Profile.sh")
+	config := filepath.Join(dir, "config", "GoPowerShellLauncher", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(config), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		shell:   "#!/bin/sh\necho 5.2.0\n",
+		profile: "### SHELL:testbash:SHELL ###\n### DESCRIPTION:Dev:DESCRIPTION ###\n",
+		config:  "shells:\n  - name: testbash\n    path: " + shell + "\n    kind: bash\n    short_names: [testbash]\nsets:\n  - name: Dev\n    shell: testbash\n    profiles: [" + profile + "]\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorder := &launcher.RecordingLauncher{}
+	m := New(noViewChanger{}, tea.WindowSizeMsg{Width: 120, Height: 30}, view.Dependencies{Launcher: recorder})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(recorder.Requests()) != 0 {
+		t.Fatal("the set was launched inside Update")
+	}
+	msg := launchFinished(t, cmd)
+	if msg.Shell != "Dev" || msg.Err != nil {
+		t.Errorf("launch result = %+v, want Dev without an error", msg)
+	}
+	if requests := recorder.Requests(); len(requests) != 1 || requests[0].Name != "Dev" {
+		t.Errorf("launched %d requests, want the Dev set", len(requests))
+	}
+}
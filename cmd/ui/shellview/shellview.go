@@ -1,19 +1,29 @@
 package shellview
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/codeviewerview"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/common"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/shortcutconfigview"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/styles"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
 )
 
+// errorStatusLifetime is how long launch errors stay in the status bar.
+const errorStatusLifetime = 10 * time.Second
+
 type model struct {
 	shellsList     list.Model
 	selected       map[int]struct{}
@@ -21,9 +31,17 @@ type model struct {
 	viewChanger    view.ViewChanger
 	loadedProfiles []types.ProfileItem
 	shortcut       bool
+	target         launcher.Mode
+	dryRun         bool
+	// spinners of the shells being launched, by list index
+	spinners     map[int]spinner.Model
+	pending      int
+	launchFailed bool
+	quitWhenDone bool
+	deps         view.Dependencies
 }
 
-func New(profiles []types.ProfileItem, windowSize tea.WindowSizeMsg, viewChanger view.ViewChanger, createShortcut bool) *model {
+func New(profiles []types.ProfileItem, windowSize tea.WindowSizeMsg, viewChanger view.ViewChanger, createShortcut bool, deps view.Dependencies) *model {
 	l.Logger.Info("Initializing shell list", "profiles", profiles)
 	shells, err := utils.LoadShells()
 	if err != nil {
@@ -38,6 +56,9 @@ func New(profiles []types.ProfileItem, windowSize tea.WindowSizeMsg, viewChanger
 			viewChanger:    viewChanger,
 			loadedProfiles: profiles,
 			shortcut:       createShortcut,
+			target:         launcher.ModeWindow,
+			spinners:       make(map[int]spinner.Model),
+			deps:           deps,
 		}
 	}
 	// Load shell items based on profiles
@@ -45,30 +66,53 @@ func New(profiles []types.ProfileItem, windowSize tea.WindowSizeMsg, viewChanger
 	for _, shell := range shells {
 		l.Logger.Info("Processing shell", "shell", shell.ItemTitle)
 		// get the profiles that use this shell
-		var profilesForShell []string
-		for _, profile := range profiles {
-			l.Logger.Info("Processing profile", "profile", profile.ItemTitle)
-			for _, shortName := range shell.ShortNames {
-				l.Logger.Info("Processing short name", "shortName", shortName)
-				profileShell := utils.NormalizeString(profile.Shell)
-				shortNameTrimmed := utils.NormalizeString(shortName)
-				l.Logger.Info("Comparing", "profile.Shell", profileShell, "shortName", shortNameTrimmed)
-				if profileShell == shortNameTrimmed {
-					l.Logger.Info("Profile uses shell", "profile", profile.ItemTitle, "shell", shortNameTrimmed)
-					profilesForShell = append(profilesForShell, profile.Path)
-					break
-				}
-			}
-		}
+		profilesForShell := utils.ProfilesForShell(profiles, shell)
 		l.Logger.Info("Profiles for shell", "shell", shell.ItemTitle, "profilesForShell", profilesForShell)
+		env, warnings := utils.MergeProfileEnv(profilesForShell)
+		workDir, workDirWarnings := utils.MergeProfileWorkDir(profilesForShell)
+		warnings = append(warnings, workDirWarnings...)
+		args, argsWarnings := utils.ResolveArgs(shell, nil, profilesForShell)
+		warnings = append(warnings, argsWarnings...)
+		incompatible, versionWarnings := utils.ShellVersionIssues(profilesMatching(profiles, profilesForShell), shell)
+		warnings = append(warnings, versionWarnings...)
+		description := shell.ItemDescription
+		if shell.Version != "" || shell.Edition != "" {
+			description += " (" + strings.TrimSpace(shell.Version+" "+shell.Edition) + ")"
+		}
+		switch {
+		case !shell.Available:
+			description += ": not available at " + shell.Path
+		case len(incompatible) > 0:
+			description += ": incompatible, " + strings.Join(incompatible, ", ")
+		default:
+			description += ": loaded profiles: " + strconv.Itoa(len(profilesForShell))
+		}
+		if workDir != "" {
+			description += ", workdir: " + workDir
+		}
+		if len(env) > 0 {
+			description += ", env: " + strings.Join(env, " ")
+		}
+		if args != nil {
+			description += ", args: " + strings.Join(args, " ")
+		}
 		shellItem := types.ShellItem{
 			ItemTitle:       shell.ItemTitle,
-			ItemDescription: shell.ItemDescription + ": loaded profiles: " + strconv.Itoa(len(profilesForShell)),
+			ItemDescription: description,
 			Name:            shell.Name,
 			ShortName:       shell.ShortName,
 			ShortNames:      shell.ShortNames,
+			Kind:            shell.Kind,
 			Path:            shell.Path,
 			ProfilePaths:    profilesForShell,
+			Env:             env,
+			WorkDir:         workDir,
+			Args:            args,
+			Edition:         shell.Edition,
+			Version:         shell.Version,
+			Available:       shell.Available,
+			Incompatible:    incompatible,
+			Warnings:        warnings,
 		}
 		items = append(items, shellItem)
 	}
@@ -93,9 +137,21 @@ func New(profiles []types.ProfileItem, windowSize tea.WindowSizeMsg, viewChanger
 		viewChanger:    viewChanger,
 		loadedProfiles: profiles,
 		shortcut:       createShortcut,
+		target:         launcher.ModeWindow,
+		spinners:       make(map[int]spinner.Model),
+		quitWhenDone:   quitAfterLaunch(),
+		deps:           deps,
 	}
 }
 
+func quitAfterLaunch() bool {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return false
+	}
+	return config.Launch.QuitAfterLaunch
+}
+
 func (m *model) Init() tea.Cmd {
 	return tea.SetWindowTitle("Select Shell")
 }
@@ -106,6 +162,31 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.windowSize = msg
 		m.shellsList.SetSize(msg.Width, msg.Height)
+	case spinner.TickMsg:
+		for i, s := range m.spinners {
+			if s.ID() != msg.ID {
+				continue
+			}
+			s, cmd = s.Update(msg)
+			m.spinners[i] = s
+			item := m.shellsList.Items()[i].(types.ShellItem)
+			item.Launch.Spinner = s.View()
+			m.shellsList.SetItem(i, item)
+			return m, cmd
+		}
+		// the launch finished, let the spinner stop
+		return m, nil
+	case launchFinishedMsg:
+		return m, m.launchFinished(msg)
+	case common.InlineLaunchFinishedMsg:
+		status := fmt.Sprintf("%s %s", msg.Shell, msg.Result)
+		if msg.Err != nil {
+			l.Logger.Error("Inline shell failed", "shell", msg.Shell, "Error", msg.Err)
+			if msg.Result.PID == 0 {
+				status = fmt.Sprintf("%s failed to start: %v", msg.Shell, msg.Err)
+			}
+		}
+		return m, m.shellsList.NewStatusMessage(styles.StatusMessageStyle(status))
 	case tea.KeyMsg:
 		switch msg.String() {
 		case " ":
@@ -116,6 +197,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 			item := items[i].(types.ShellItem)
+			if !item.Launchable() {
+				return m, m.unavailable(item)
+			}
 			if _, ok := m.selected[i]; ok {
 				delete(m.selected, i)
 				l.Logger.Debug("Deselected shell", "index", i)
@@ -143,26 +227,89 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					l.Logger.Error("Invalid index", "index", i)
 					break
 				}
+				if item := m.shellsList.Items()[i].(types.ShellItem); !item.Launchable() {
+					return m, m.unavailable(item)
+				}
 				m.selected[i] = struct{}{}
 			}
 			var selectedShells []types.ShellItem
-			for i := range m.selected {
+			for _, i := range common.SortedSelection(m.selected) {
 				item := m.shellsList.Items()[i].(types.ShellItem)
 				selectedShells = append(selectedShells, item)
 			}
 			if m.shortcut {
-				return m, m.viewChanger.ChangeView(shortcutconfigview.New(m.viewChanger, m.windowSize, m.loadedProfiles, selectedShells), false)
+				return m, m.viewChanger.ChangeView(shortcutconfigview.New(m.viewChanger, m.windowSize, m.loadedProfiles, selectedShells, m.dryRun), false)
+			} else if m.dryRun {
+				return m, m.showDryRun(selectedShells)
 			} else {
-				l.Logger.Info("Launching selected shells", "selected", m.selected, "profiles", m.loadedProfiles)
-				for i := range m.selected {
-					merged := utils.MergeSelectedProfiles(m.shellsList.Items()[i].(types.ShellItem).ProfilePaths)
+				l.Logger.Info("Launching selected shells", "selected", m.selected, "profiles", m.loadedProfiles, "target", m.target)
+				var warnings []string
+				var launches, inlineLaunches []tea.Cmd
+				for _, i := range common.SortedSelection(m.selected) {
 					item := m.shellsList.Items()[i].(types.ShellItem)
-					err := launcher.ExecutePowerShellProcess(merged, item.Path)
-					if err != nil {
-						l.Logger.Error("Failed to execute PowerShell process", "Error", err)
+					if item.Launch.Running {
+						continue
 					}
+					req := utils.NewLaunchRequest(item, item.ProfilePaths, item.Env, item.WorkDir, item.Args)
+					req.Mode = m.target
+					warnings = append(warnings, item.Warnings...)
+					if m.target == launcher.ModeInline {
+						shell := item.Name
+						inlineLaunches = append(inlineLaunches, common.LaunchInline(m.deps.Launcher, req, func(result launcher.Result, err error) tea.Msg {
+							return common.InlineLaunchFinishedMsg{Shell: shell, Result: result, Err: err}
+						}))
+						continue
+					}
+					launches = append(launches, m.startLaunch(i, item, req))
+				}
+				if len(inlineLaunches) > 0 {
+					return m, tea.Sequence(inlineLaunches...)
 				}
+				status := fmt.Sprintf("Launching %d shell(s)", len(launches))
+				if len(warnings) > 0 {
+					status += "; Warning: " + strings.Join(warnings, "; ")
+				}
+				return m, tea.Batch(append(launches, m.shellsList.NewStatusMessage(styles.StatusMessageStyle(status)))...)
+			}
+		case "a":
+			m.quitWhenDone = !m.quitWhenDone
+			l.Logger.Debug("Toggled quit after launch", "quitWhenDone", m.quitWhenDone)
+			status := "Stay open after launching"
+			if m.quitWhenDone {
+				status = "Quit once all launches succeed"
+			}
+			return m, m.shellsList.NewStatusMessage(styles.StatusMessageStyle(status))
+		case "i":
+			if m.target == launcher.ModeInline {
+				m.target = launcher.ModeWindow
+			} else {
+				m.target = launcher.ModeInline
 			}
+			l.Logger.Debug("Toggled inline launch", "target", m.target)
+			return m, m.shellsList.NewStatusMessage(styles.StatusMessageStyle(targetStatus(m.target)))
+		case "d":
+			m.dryRun = !m.dryRun
+			l.Logger.Debug("Toggled dry run", "dryRun", m.dryRun)
+			status := "Dry run off"
+			if m.dryRun {
+				status = "Dry run: enter shows what would be launched"
+			}
+			return m, m.shellsList.NewStatusMessage(styles.StatusMessageStyle(status))
+		case "t":
+			m.target = nextTarget(m.target)
+			l.Logger.Debug("Changed launch target", "target", m.target)
+			return m, m.shellsList.NewStatusMessage(styles.StatusMessageStyle(targetStatus(m.target)))
+		case "p":
+			// preview the merged script for the highlighted shell
+			i := m.shellsList.Index()
+			if i < 0 || i >= len(m.shellsList.Items()) {
+				l.Logger.Error("Invalid index", "index", i)
+				break
+			}
+			item := m.shellsList.Items()[i].(types.ShellItem)
+			req := utils.NewLaunchRequest(item, item.ProfilePaths, item.Env, item.WorkDir, item.Args)
+			title := fmt.Sprintf("Preview (%s): %d profile(s)", item.Name, len(item.ProfilePaths))
+			return m, m.viewChanger.ChangeView(codeviewerview.NewFromContent(title, req.Script, req.Adapter().Kind(), m.windowSize, m.viewChanger), false)
 		}
 	}
 
@@ -170,6 +317,144 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// launchFinishedMsg is sent when a launch started from the list returns.
+type launchFinishedMsg struct {
+	index  int
+	shell  string
+	result launcher.Result
+	err    error
+}
+
+// startLaunch shows a spinner next to the shell and returns the command that launches it in the background.
+func (m *model) startLaunch(i int, item types.ShellItem, req launcher.Request) tea.Cmd {
+	s := spinner.New(spinner.WithSpinner(spinner.Dot))
+	m.spinners[i] = s
+	m.pending++
+	item.Launch = types.LaunchState{Running: true, Spinner: s.View()}
+	m.shellsList.SetItem(i, item)
+	launch := m.deps.Launcher
+	return tea.Batch(s.Tick, func() tea.Msg {
+		result, err := launch.Launch(req)
+		return launchFinishedMsg{index: i, shell: item.Name, result: result, err: err}
+	})
+}
+
+// launchFinished shows the result next to the shell, and quits once every launch succeeded when asked to.
+func (m *model) launchFinished(msg launchFinishedMsg) tea.Cmd {
+	delete(m.spinners, msg.index)
+	m.pending--
+	item := m.shellsList.Items()[msg.index].(types.ShellItem)
+	item.Launch = types.LaunchState{Message: msg.result.String()}
+	var cmd tea.Cmd
+	if msg.err != nil {
+		l.Logger.Error("Failed to launch shell", "shell", msg.shell, "Error", msg.err)
+		m.launchFailed = true
+		var earlyErr *launcher.EarlyExitError
+		switch {
+		case errors.As(msg.err, &earlyErr):
+			item.Launch.Message = "crashed: " + strings.ReplaceAll(msg.err.Error(), "\n", " ")
+		case msg.result.PID == 0:
+			item.Launch.Message = fmt.Sprintf("failed to start: %v", msg.err)
+		default:
+			item.Launch.Message = fmt.Sprintf("%s: %v", msg.result, msg.err)
+		}
+		item.Launch.Failed = true
+		cmd = m.errorStatus(fmt.Sprintf("%s %s", msg.shell, item.Launch.Message))
+	} else {
+		l.Logger.Info("Shell launched", "shell", msg.shell, "result", msg.result.String())
+	}
+	m.shellsList.SetItem(msg.index, item)
+	if m.pending > 0 {
+		return cmd
+	}
+	failed := m.launchFailed
+	m.launchFailed = false
+	if m.quitWhenDone && !failed {
+		l.Logger.Info("All launches succeeded, quitting")
+		return tea.Quit
+	}
+	return cmd
+}
+
+// errorStatus shows an error in the status bar for longer than other messages.
+func (m *model) errorStatus(status string) tea.Cmd {
+	lifetime := m.shellsList.StatusMessageLifetime
+	m.shellsList.StatusMessageLifetime = errorStatusLifetime
+	cmd := m.shellsList.NewStatusMessage(styles.ErrorMessageStyle(status))
+	m.shellsList.StatusMessageLifetime = lifetime
+	return cmd
+}
+
+func (m *model) unavailable(item types.ShellItem) tea.Cmd {
+	status := fmt.Sprintf("%s is not available at %s", item.Name, item.Path)
+	if item.Available {
+		status = fmt.Sprintf("%s %s is incompatible: %s", item.Name, item.Version, strings.Join(item.Incompatible, "; "))
+	}
+	l.Logger.Warn("Shell cannot be launched", "shell", item.Name, "reason", status)
+	return m.shellsList.NewStatusMessage(styles.StatusMessageStyle(status))
+}
+
+// showDryRun opens the launch plans of the shells in the code viewer instead of launching them.
+func (m *model) showDryRun(shells []types.ShellItem) tea.Cmd {
+	var plans []string
+	for _, item := range shells {
+		req := utils.NewLaunchRequest(item, item.ProfilePaths, item.Env, item.WorkDir, item.Args)
+		req.Mode = m.target
+		plan, err := launcher.NewPlan(req)
+		if err != nil {
+			l.Logger.Error("Failed to plan launch", "shell", item.Name, "Error", err)
+			plans = append(plans, fmt.Sprintf("# %s cannot be launched: %v\n", item.Name, err))
+			continue
+		}
+		plan.Warnings = append(plan.Warnings, item.Warnings...)
+		plans = append(plans, plan.String())
+	}
+	title := fmt.Sprintf("Dry run: %d shell(s)", len(shells))
+	return m.viewChanger.ChangeView(codeviewerview.NewFromContent(title, strings.Join(plans, "\n"), "", m.windowSize, m.viewChanger), false)
+}
+
+// nextTarget returns the launch target after the current one, the tmux targets are skipped outside tmux.
+func nextTarget(current launcher.Mode) launcher.Mode {
+	for i, mode := range launcher.Modes {
+		if mode != current {
+			continue
+		}
+		for j := 1; j < len(launcher.Modes); j++ {
+			next := launcher.Modes[(i+j)%len(launcher.Modes)]
+			if !next.Tmux() || launcher.InTmux() {
+				return next
+			}
+		}
+	}
+	return launcher.ModeWindow
+}
+
+func targetStatus(target launcher.Mode) string {
+	switch target {
+	case launcher.ModeInline:
+		return "Launch inline in this terminal"
+	case launcher.ModeTmux:
+		return "Launch in new tmux windows"
+	case launcher.ModeTmuxSplit:
+		return "Launch in new tmux panes"
+	}
+	return "Launch in new window"
+}
+
+// profilesMatching returns the profiles with the paths, in the order of the paths.
+func profilesMatching(profiles []types.ProfileItem, paths []string) []types.ProfileItem {
+	var matching []types.ProfileItem
+	for _, path := range paths {
+		for _, profile := range profiles {
+			if profile.Path == path {
+				matching = append(matching, profile)
+				break
+			}
+		}
+	}
+	return matching
+}
+
 func (m *model) View() string {
 	return m.shellsList.View()
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/codeviewerview"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/ui/view"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
 )
@@ -33,12 +34,17 @@ type model struct {
 	viewChanger view.ViewChanger
 	profiles    []types.ProfileItem
 	shell       []types.ShellItem
+	// dryRun shows the shortcuts that would be created instead of creating them
+	dryRun bool
+	// err is the reason the last shortcut could not be created
+	err error
 }
 
-func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg, profiles []types.ProfileItem, shell []types.ShellItem) *model {
+func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg, profiles []types.ProfileItem, shell []types.ShellItem, dryRun bool) *model {
 	m := &model{
 		inputs:      make([]textinput.Model, 2),
 		shell:       shell,
+		dryRun:      dryRun,
 		profiles:    profiles,
 		viewChanger: viewChanger,
 		windowSize:  windowSize,
@@ -89,7 +95,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if s == "enter" && m.focusIndex == len(m.inputs) {
 				name := m.inputs[0].Value()
 				destination := m.inputs[1].Value()
-				l.Logger.Info("Creating shortcut", "name", name, "destination", destination, "profiles", m.profiles)
+				l.Logger.Info("Creating shortcut", "name", name, "destination", destination, "profiles", m.profiles, "dryRun", m.dryRun)
+				var plans []string
 				for _, s := range m.shell {
 					l.Logger.Info("Creating shortcut", "name", name)
 					name = name + "_" + s.Name
@@ -102,15 +109,41 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						}
 					}
 					if len(profilesArray) != 0 {
-						err := utils.CreateShortcut(profilesArray, name, destination, s.GetShortName())
+						// the shortcut launches a set with the same name as the shortcut
+						set := utils.Set{Name: name, Shell: s.GetShortName(), Profiles: profilesArray}
+						if m.dryRun {
+							if err := utils.CheckShortcutSet(set); err != nil {
+								plans = append(plans, fmt.Sprintf("# %s cannot be created: %v\n", name, err))
+								continue
+							}
+							plan, err := utils.PlanShortcut(set, utils.Shortcut{Name: name, Destination: destination})
+							if err != nil {
+								plans = append(plans, fmt.Sprintf("# %s cannot be created: %v\n", name, err))
+								continue
+							}
+							plans = append(plans, fmt.Sprintf("%sProfiles: %s\n", plan, strings.Join(set.Profiles, ", ")))
+							continue
+						}
+						// an existing set with other profiles is not replaced, pick another name or edit the set
+						if err := utils.SaveShortcutSet(set, false); err != nil {
+							l.Logger.Error("Failed to save set for shortcut", "Error", err)
+							m.err = err
+							return m, nil
+						}
+						err := utils.CreateShortcut(set, utils.Shortcut{Name: name, Destination: destination})
 						if err != nil {
 							l.Logger.Error("Failed to create shortcut", "Error", err)
+							m.err = err
 							return m, nil
 						}
 					} else {
 						l.Logger.Warn("No profiles found for shell", "shell", s.Name)
 					}
 				}
+				if m.dryRun {
+					title := fmt.Sprintf("Dry run: %d shortcut(s)", len(plans))
+					return m, m.viewChanger.ChangeView(codeviewerview.NewFromContent(title, strings.Join(plans, "\n"), "", m.windowSize, m.viewChanger), false)
+				}
 				return m, tea.Quit
 			}
 
@@ -192,6 +225,10 @@ func (m *model) View() string {
 		}
 	}
 
+	if m.err != nil {
+		errString += m.err.Error()
+	}
+
 	button := &blurredButton
 	if m.focusIndex == len(m.inputs) {
 		button = &focusedButton
@@ -17,9 +17,10 @@ type model struct {
 	selected     map[int]struct{}
 	windowSize   tea.WindowSizeMsg
 	viewChanger  view.ViewChanger
+	deps         view.Dependencies
 }
 
-func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg) *model {
+func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg, deps view.Dependencies) *model {
 	l.Logger.Debug("Initializing profile list")
 	loadConfig, err := utils.LoadConfig()
 	if err != nil {
@@ -34,16 +35,9 @@ func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg) *model {
 	}
 
 	var items []list.Item
+	// the loaded profiles are listed as they are, the shell view needs their headers
 	for _, p := range profiles {
-		item := types.ProfileItem{
-			ItemTitle:       p.ItemTitle,
-			ItemDescription: p.ItemDescription,
-			IsValid:         p.IsValid,
-			Path:            p.Path,
-			Shell:           p.Shell,
-			Name:            p.Name,
-		}
-		items = append(items, item)
+		items = append(items, p)
 	}
 	delegateKeyMap, err := styles.NewProfileDelegateKeyMap()
 	if err != nil {
@@ -71,6 +65,7 @@ func New(viewChanger view.ViewChanger, windowSize tea.WindowSizeMsg) *model {
 		selected:     make(map[int]struct{}),
 		viewChanger:  viewChanger,
 		windowSize:   windowSize,
+		deps:         deps,
 	}
 }
 
@@ -136,7 +131,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			// open shellview with profiles selected
 			l.Logger.Info("Selected profiles", "profiles", selectedProfiles)
-			return m, m.viewChanger.ChangeView(shellview.New(selectedProfiles, m.windowSize, m.viewChanger, true), true)
+			return m, m.viewChanger.ChangeView(shellview.New(selectedProfiles, m.windowSize, m.viewChanger, true, m.deps), true)
 		case "v":
 			// view profile content
 			i := m.profilesList.Index()
@@ -145,6 +140,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 			item := m.profilesList.Items()[i].(types.ProfileItem)
+			if line := item.FirstIssueLine(); line > 0 {
+				return m, m.viewChanger.ChangeView(codeviewerview.NewAtLine(item.Path, line, m.windowSize, m.viewChanger), true)
+			}
 			return m, m.viewChanger.ChangeView(codeviewerview.New(item.Path, m.windowSize, m.viewChanger), true)
 		}
 	}
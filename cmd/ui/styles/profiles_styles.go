@@ -55,6 +55,9 @@ func (d ProfileItemDelegate) Render(w io.Writer, m list.Model, index int, item l
 	if i, ok := item.(types.ProfileItem); ok {
 		title = fmt.Sprintf("%s | %s | Defined Shells: %s", i.GetName(), valid, i.GetShell())
 		desc = i.GetDescription()
+		if len(i.Issues) > 0 {
+			desc = i.Issues[0].String()
+		}
 	} else {
 		return
 	}
@@ -207,6 +210,9 @@ type profiledelegateKeyMap struct {
 	selected   key.Binding
 	unselected key.Binding
 	view       key.Binding
+	preview    key.Binding
+	diff       key.Binding
+	saveSet    key.Binding
 	backpage   key.Binding
 }
 
@@ -214,6 +220,7 @@ func (d profiledelegateKeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{
 		d.selected,
 		d.view,
+		d.preview,
 		d.backpage,
 	}
 }
@@ -228,6 +235,9 @@ func (d profiledelegateKeyMap) FullHelp() [][]key.Binding {
 		},
 		{
 			d.view,
+			d.preview,
+			d.diff,
+			d.saveSet,
 			d.backpage,
 		},
 	}
@@ -243,6 +253,18 @@ func NewProfileDelegateKeyMap() (*profiledelegateKeyMap, error) {
 			key.WithKeys("v"),
 			key.WithHelp("v", "View Profile"),
 		),
+		preview: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "Preview Script"),
+		),
+		diff: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "Diff Two Selected"),
+		),
+		saveSet: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "Save as Set"),
+		),
 		backpage: key.NewBinding(
 			key.WithKeys("ctrl+left"),
 			key.WithHelp("ctrl+←", "Back Page"),
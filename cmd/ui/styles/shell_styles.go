@@ -53,6 +53,16 @@ func (d ShellItemDelegate) Render(w io.Writer, m list.Model, index int, item lis
 		return
 	}
 
+	var launchState string
+	switch {
+	case i.Launch.Running:
+		launchState = s.Checked.Render(i.Launch.Spinner + " launching")
+	case i.Launch.Failed:
+		launchState = ErrorMessageStyle("✗ " + i.Launch.Message)
+	case i.Launch.Message != "":
+		launchState = StatusMessageStyle(i.Launch.Message)
+	}
+
 	if m.Width() <= 0 {
 		// short-circuit
 		return
@@ -82,6 +92,14 @@ func (d ShellItemDelegate) Render(w io.Writer, m list.Model, index int, item lis
 	if emptyFilter {
 		title = s.DimmedTitle.Render("   " + title)
 		desc = s.DimmedDesc.Render("   " + desc)
+	} else if !i.Launchable() {
+		// unavailable and incompatible shells stay dimmed, the pointer still shows the highlighted one
+		pointer := "   "
+		if isSelected {
+			pointer = "👉 "
+		}
+		title = s.DimmedTitle.Render(pointer + title)
+		desc = s.DimmedDesc.Render("   " + desc)
 	} else if isSelected && m.FilterState() != list.Filtering {
 		if isFiltered {
 			// Highlight matches
@@ -102,6 +120,12 @@ func (d ShellItemDelegate) Render(w io.Writer, m list.Model, index int, item lis
 		desc = s.NormalDesc.Render("   " + desc)
 	}
 
+	if launchState != "" {
+		// the state goes after the title, cut to what is left of the line
+		room := textwidth - ansi.StringWidth(title) - ansi.StringWidth(selectedShell) - 2
+		selectedShell += " " + ansi.Truncate(launchState, max(room, 0), ellipsis)
+	}
+
 	if d.ShowDescription {
 		fmt.Fprintf(w, "%s %s\n%s", title, selectedShell, desc) //nolint: errcheck
 		return
@@ -200,12 +224,18 @@ func NewShellItemDelegate(keys *shelldelegateKeyMap) (*ShellItemDelegate, error)
 type shelldelegateKeyMap struct {
 	selected   key.Binding
 	unselected key.Binding
+	preview    key.Binding
+	inline     key.Binding
+	target     key.Binding
+	dryRun     key.Binding
+	quit       key.Binding
 	backpage   key.Binding
 }
 
 func (d shelldelegateKeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{
 		d.selected,
+		d.preview,
 		d.backpage,
 	}
 }
@@ -220,6 +250,11 @@ func (d shelldelegateKeyMap) FullHelp() [][]key.Binding {
 		},
 		{
 			d.unselected,
+			d.preview,
+			d.inline,
+			d.target,
+			d.dryRun,
+			d.quit,
 		},
 	}
 }
@@ -230,6 +265,26 @@ func NewShellDelegateKeyMap() (*shelldelegateKeyMap, error) {
 			key.WithKeys(" "),
 			key.WithHelp("space", "(De)Select Shell"),
 		),
+		preview: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "Preview Script"),
+		),
+		inline: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "Toggle Inline Launch"),
+		),
+		target: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "Cycle Launch Target"),
+		),
+		dryRun: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "Toggle Dry Run"),
+		),
+		quit: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "Toggle Quit After Launch"),
+		),
 		backpage: key.NewBinding(
 			key.WithKeys("ctrl+left"),
 			key.WithHelp("ctrl+←", "Back Page"),
@@ -43,4 +43,51 @@ var (
 
 var (
 	StatusMessageStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF94F4")).Bold(true).Render
+	ErrorMessageStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#C00000", Dark: "#FF5F5F"}).Bold(true).Render
+)
+
+// Diff view styles
+
+var (
+	DiffAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#008A00", Dark: "#5FD75F"})
+	DiffRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#C00000", Dark: "#FF5F5F"})
+	DiffHunkStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#008A74", Dark: "#40C1AC"}).Bold(true)
+	DiffGutterStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"})
+)
+
+// Code viewer syntax highlighting styles
+
+type SyntaxStyles struct {
+	Keyword    lipgloss.Style
+	Variable   lipgloss.Style
+	String     lipgloss.Style
+	HereString lipgloss.Style
+	Comment    lipgloss.Style
+	Cmdlet     lipgloss.Style
+	Operator   lipgloss.Style
+	Parameter  lipgloss.Style
+	Number     lipgloss.Style
+}
+
+func NewDefaultSyntaxStyles() (s SyntaxStyles) {
+	s.Keyword = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#B000B0", Dark: "#FF94F4"}).Bold(true)
+	s.Variable = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#008A74", Dark: "#40C1AC"})
+	s.String = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#8A5A00", Dark: "#E5C07B"})
+	s.HereString = s.String
+	s.Comment = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"}).Italic(true)
+	s.Cmdlet = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0060C0", Dark: "#61AFEF"})
+	s.Operator = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#C05000", Dark: "#FF99FF"})
+	s.Parameter = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#505050", Dark: "#ABB2BF"})
+	s.Number = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#C05000", Dark: "#D19A66"})
+	return s
+}
+
+var SyntaxStyle = NewDefaultSyntaxStyles()
+
+// Code viewer search and gutter styles
+
+var (
+	LineNumberStyle         = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"})
+	SearchMatchStyle        = lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "#FFE9A8", Dark: "#5C4B00"})
+	CurrentSearchMatchStyle = lipgloss.NewStyle().Background(lipgloss.Color("#FF94F4")).Foreground(lipgloss.Color("#000000")).Bold(true)
 )
@@ -1,11 +1,21 @@
 package view
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+)
 
 type ViewChanger interface {
 	ChangeView(newView tea.Model, ClearSelections bool) tea.Cmd
+	// Back returns to the previous view, as ctrl+left does.
+	Back() tea.Cmd
 }
 
 type Clearable interface {
 	ClearSelectedItems()
 }
+
+// Dependencies are the services views use, handed down from the main model.
+type Dependencies struct {
+	Launcher launcher.Launcher
+}
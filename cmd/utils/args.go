@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
+)
+
+// SplitArgs splits an argument string on whitespace. Single or double quotes group an argument containing spaces.
+func SplitArgs(input string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range input {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in arguments: %s", quote, input)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// JoinArgs joins arguments so SplitArgs returns them unchanged, as long as no argument contains both kinds of quote.
+func JoinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		switch {
+		case arg == "" || strings.ContainsAny(arg, " \t'") && !strings.Contains(arg, `"`):
+			arg = `"` + arg + `"`
+		case strings.Contains(arg, `"`):
+			arg = "'" + arg + "'"
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// MergeProfileArgs returns the shell arguments declared by the first profile with an ARGS header, nil when none has one.
+// Profiles declaring different arguments produce a warning.
+func MergeProfileArgs(profilePaths []string) ([]string, []string) {
+	var args []string
+	var source string
+	var warnings []string
+	for _, path := range profilePaths {
+		profile, err := GetProfileProperties(path)
+		if err != nil {
+			l.Logger.Warn("Failed to get profile properties", "path", path, "error", err)
+			continue
+		}
+		if profile.Args == nil {
+			continue
+		}
+		if args == nil {
+			args = profile.Args
+			source = profile.GetName()
+			continue
+		}
+		if strings.Join(args, "\x00") != strings.Join(profile.Args, "\x00") {
+			l.Logger.Warn("Conflicting shell arguments", "args", args, "ignored", profile.Args, "profile", path)
+			warnings = append(warnings, fmt.Sprintf("arguments %q from %s ignored, using %q from %s", strings.Join(profile.Args, " "), profile.GetName(), strings.Join(args, " "), source))
+		}
+	}
+	return args, warnings
+}
+
+// ResolveArgs picks the shell arguments for a launch. A set overrides the profile ARGS headers, which override the
+// shell configuration. Nil means the launcher defaults.
+func ResolveArgs(shell types.ShellItem, setArgs []string, profilePaths []string) ([]string, []string) {
+	args := shell.Args
+	profileArgs, warnings := MergeProfileArgs(profilePaths)
+	if profileArgs != nil {
+		args = profileArgs
+	}
+	if setArgs != nil {
+		args = setArgs
+	}
+	return args, warnings
+}
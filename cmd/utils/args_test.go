@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+		err   bool
+	}{
+		{"", nil, false},
+		{"   \t ", nil, false},
+		{"-NoLogo -NoProfile", []string{"-NoLogo", "-NoProfile"}, false},
+		{"  -a \t -b  ", []string{"-a", "-b"}, false},
+		{`-Command "Write-Host hi"`, []string{"-Command", "Write-Host hi"}, false},
+		{`-Command 'Write-Host "hi"'`, []string{"-Command", `Write-Host "hi"`}, false},
+		{`"it's"`, []string{"it's"}, false},
+		{`-Path="C:\Program Files"\bin`, []string{`-Path=C:\Program Files\bin`}, false},
+		{`"" ''`, []string{"", ""}, false},
+		{`a"b c"d`, []string{"ab cd"}, false},
+		{`"unterminated`, nil, true},
+		{`-a 'open`, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := SplitArgs(tt.input)
+		if (err != nil) != tt.err {
+			t.Errorf("SplitArgs(%q) error = %v, want error %v", tt.input, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitArgs(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestJoinArgsRoundTrip(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"-NoLogo", "-NoExit"}, "-NoLogo -NoExit"},
+		{[]string{"-Command", "Write-Host hi"}, `-Command "Write-Host hi"`},
+		{[]string{"tab\there"}, "\"tab\there\""},
+		{[]string{""}, `""`},
+		{[]string{"it's"}, `"it's"`},
+		{[]string{`say "hi"`}, `'say "hi"'`},
+		{[]string{`a"b`}, `'a"b'`},
+		{[]string{`C:\Program Files\PowerShell\7\pwsh.exe`, "-File", `C:\a b\c.ps1`}, `"C:\Program Files\PowerShell\7\pwsh.exe" -File "C:\a b\c.ps1"`},
+	}
+	for _, tt := range tests {
+		joined := JoinArgs(tt.args)
+		if joined != tt.want {
+			t.Errorf("JoinArgs(%q) = %q, want %q", tt.args, joined, tt.want)
+		}
+		split, err := SplitArgs(joined)
+		if err != nil {
+			t.Errorf("SplitArgs(%q): %v", joined, err)
+			continue
+		}
+		if len(split) != len(tt.args) || len(split) > 0 && !reflect.DeepEqual(split, tt.args) {
+			t.Errorf("SplitArgs(JoinArgs(%q)) = %q", tt.args, split)
+		}
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"runtime"
 	"strconv"
+	"time"
 
-	shortcut "github.com/nyaosorg/go-windows-shortcut"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/shelllink"
 	"github.com/spf13/viper"
 )
 
@@ -17,13 +19,42 @@ type Profile struct {
 	Path string `mapstructure:"path"`
 }
 
+// Shortcut launches a profile set. Profiles is only read from configurations written before sets existed.
 type Shortcut struct {
 	ID          string    `mapstructure:"id"`
 	Name        string    `mapstructure:"name"`
 	Destination string    `mapstructure:"destination"`
+	Set         string    `mapstructure:"set"`
+	HotKey      string    `mapstructure:"hotkey"`
 	Profiles    []Profile `mapstructure:"profiles"`
 }
 
+// Set is a named, ordered list of profiles launched together in a shell.
+// Args overrides the shell arguments when set.
+type Set struct {
+	Name     string   `mapstructure:"name"`
+	Shell    string   `mapstructure:"shell"`
+	Profiles []string `mapstructure:"profiles"`
+	WorkDir  string   `mapstructure:"workdir"`
+	Inline   bool     `mapstructure:"inline"`
+	Args     []string `mapstructure:"args"`
+	// Icon and ColorScheme are used by the terminal profile exports, Icon also by shortcuts.
+	Icon        string `mapstructure:"icon"`
+	ColorScheme string `mapstructure:"color_scheme"`
+}
+
+// ShellConfig configures a shell, matched by its short name such as pwsh. A shell that is not discovered is added.
+type ShellConfig struct {
+	Name       string   `mapstructure:"name"`
+	Title      string   `mapstructure:"title"`
+	ShortNames []string `mapstructure:"short_names"`
+	Path       string   `mapstructure:"path"`
+	Args       []string `mapstructure:"args"`
+	Edition    string   `mapstructure:"edition"`
+	// Kind is powershell, bash or zsh, guessed from the executable name when empty.
+	Kind string `mapstructure:"kind"`
+}
+
 type Config struct {
 	Profile struct {
 		Path      string `mapstructure:"path"`
@@ -34,7 +65,37 @@ type Config struct {
 		File  string `mapstructure:"file"`
 		Level string `mapstructure:"level"`
 	} `mapstructure:"logging"`
-	Shortcuts []Shortcut `mapstructure:"shortcuts"`
+	Terminal struct {
+		Command []string `mapstructure:"command"`
+	} `mapstructure:"terminal"`
+	Launch struct {
+		// GracePeriod is how long a new shell is watched for exiting with an error, 0 turns it off.
+		GracePeriod time.Duration `mapstructure:"grace_period"`
+		// QuitAfterLaunch quits the UI once every shell launched from the shell view started.
+		QuitAfterLaunch bool `mapstructure:"quit_after_launch"`
+	} `mapstructure:"launch"`
+	Tmux struct {
+		// Split is the direction of tmux-split launches, vertical or horizontal.
+		Split string `mapstructure:"split"`
+	} `mapstructure:"tmux"`
+	Viewer struct {
+		Highlight bool `mapstructure:"highlight"`
+	} `mapstructure:"viewer"`
+	Scripts struct {
+		Dir    string        `mapstructure:"dir"`
+		MaxAge time.Duration `mapstructure:"max_age"`
+	} `mapstructure:"scripts"`
+	Export struct {
+		// Executable is the launcher path written into exported profiles, the running executable when empty.
+		Executable      string `mapstructure:"executable"`
+		WindowsTerminal struct {
+			Path        string `mapstructure:"path"`
+			ColorScheme string `mapstructure:"color_scheme"`
+		} `mapstructure:"wt"`
+	} `mapstructure:"export"`
+	Shells    []ShellConfig `mapstructure:"shells"`
+	Sets      []Set         `mapstructure:"sets"`
+	Shortcuts []Shortcut    `mapstructure:"shortcuts"`
 }
 
 var UserConfigDir string
@@ -57,10 +118,13 @@ func LoadConfig() (*Config, error) {
 		log.Printf("Error getting current user: %v", err)
 		return nil, fmt.Errorf("error getting current user: %w", err)
 	}
-	UserConfigDir := filepath.Join(usr.HomeDir, "Documents", "GoPowerShellLauncher")
+	UserConfigDir = defaultUserConfigDir(usr.HomeDir)
 
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
+	viper.SetDefault("viewer.highlight", true)
+	viper.SetDefault("scripts.max_age", "24h")
+	viper.SetDefault("launch.grace_period", "3s")
 	viper.AddConfigPath(UserConfigDir)
 
 	exe, exeerr := os.Executable()
@@ -70,13 +134,13 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("error getting executable: %w", exeerr)
 	}
 	if filepath.Ext(exe) == ".lnk" {
-		exePath, _, direrr := shortcut.Read(exe)
+		link, direrr := shelllink.ReadFile(exe)
 		if direrr != nil {
 			log.Printf("Error reading shortcut: %v", direrr)
 			return nil, fmt.Errorf("error reading shortcut: %w", direrr)
 		}
-		if exePath != "" {
-			exeDir = filepath.Dir(exePath)
+		if link.Target != "" {
+			exeDir = filepath.Dir(link.Target)
 		} else {
 			exeDir = ""
 		}
@@ -122,6 +186,17 @@ func LoadConfig() (*Config, error) {
 	return config, nil
 }
 
+// defaultUserConfigDir keeps the Documents folder on Windows and follows the platform convention elsewhere.
+func defaultUserConfigDir(homeDir string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(homeDir, "Documents", "GoPowerShellLauncher")
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(configDir, "GoPowerShellLauncher")
+	}
+	return filepath.Join(homeDir, ".config", "GoPowerShellLauncher")
+}
+
 func GenerateUniqueID() string {
 	config, err := LoadConfig()
 	if err != nil {
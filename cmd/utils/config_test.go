@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// useTestConfig loads content as the configuration from a config.yaml in a temporary directory,
+// which is returned. Saving the configuration writes that file.
+func useTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// the shell version cache is written to the user cache directory
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	viper.Reset()
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	config = &Config{}
+	if err := viper.Unmarshal(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		config = nil
+		viper.Reset()
+	})
+	return dir
+}
+
+// writeTestShell writes an executable that answers the bash version probe, for configurations that
+// need an available shell.
+func writeTestShell(t *testing.T, dir string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test shell is a shell script")
+	}
+	path := filepath.Join(dir, "testbash")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho 5.2.0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeTestProfile writes a profile with the headers to dir and returns its path.
+func writeTestProfile(t *testing.T, dir string, name string, headers ...string) string {
+	t.Helper()
+	content := ""
+	for _, header := range headers {
+		content += "### " + header + " ###\n"
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content+"echo loaded\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testShellConfig is a configuration with the test shell as testbash.
+func testShellConfig(shellPath string) string {
+	return "shells:\n  - name: testbash\n    path: " + shellPath + "\n    kind: bash\n    short_names: [testbash]\n"
+}
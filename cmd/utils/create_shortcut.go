@@ -3,21 +3,71 @@ package utils
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/shelllink"
 )
 
-func CreateShortcut(profilepaths []string, name string, path string, shell string) error {
-	l.Logger.Info("Creating shortcut", "name", name, "path", path)
+// ShortcutPlan is the shortcut CreateShortcut writes for a set, reported by a dry run.
+type ShortcutPlan struct {
+	Set         string `json:"set"`
+	Path        string `json:"path"`
+	Target      string `json:"target"`
+	Arguments   string `json:"arguments"`
+	WorkDir     string `json:"workDir"`
+	Description string `json:"description"`
+	Icon        string `json:"icon,omitempty"`
+	HotKey      string `json:"hotKey,omitempty"`
+}
+
+func (p ShortcutPlan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Shortcut: %s\n", p.Path)
+	fmt.Fprintf(&b, "Set: %s\n", p.Set)
+	fmt.Fprintf(&b, "Target: %s\n", p.Target)
+	fmt.Fprintf(&b, "Arguments: %s\n", p.Arguments)
+	fmt.Fprintf(&b, "Working directory: %s\n", p.WorkDir)
+	fmt.Fprintf(&b, "Description: %s\n", p.Description)
+	if p.Icon != "" {
+		fmt.Fprintf(&b, "Icon: %s\n", p.Icon)
+	}
+	if p.HotKey != "" {
+		fmt.Fprintf(&b, "Hot key: %s\n", p.HotKey)
+	}
+	return b.String()
+}
+
+// PlanShortcut validates the shortcut for a set and returns what CreateShortcut would write, without writing it.
+func PlanShortcut(set Set, shortcut Shortcut) (ShortcutPlan, error) {
+	name, path := shortcut.Name, shortcut.Destination
+	profilepaths := set.Profiles
+	workDir := set.WorkDir
+	if workDir != "" {
+		// the shortcut starts where a launch of the set would
+		resolved, err := ResolveWorkDir(workDir)
+		if err != nil {
+			l.Logger.Error("Invalid set working directory", "set", set.Name, "error", err)
+			return ShortcutPlan{}, err
+		}
+		workDir = resolved
+	} else {
+		var warnings []string
+		workDir, warnings = MergeProfileWorkDir(profilepaths)
+		for _, warning := range warnings {
+			l.Logger.Warn("Shortcut working directory", "warning", warning)
+		}
+	}
+	l.Logger.Info("Planning shortcut", "name", name, "path", path, "set", set.Name, "workDir", workDir)
 	if name == "" {
 		l.Logger.Error("Shortcut name is null")
-		return fmt.Errorf("shortcut name cannot be null")
+		return ShortcutPlan{}, fmt.Errorf("shortcut name cannot be null")
 	}
 	// Check if the path exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		l.Logger.Error("Destination path is not valid", "destination", path)
-		return fmt.Errorf("destination path is not valid")
+		return ShortcutPlan{}, fmt.Errorf("destination path is not valid")
 	}
 	l.Logger.Info("Path exists", "path", path)
 
@@ -26,71 +76,74 @@ func CreateShortcut(profilepaths []string, name string, path string, shell strin
 		_, err := os.Stat(profilepath)
 		if err != nil {
 			l.Logger.Error("Profile path doesn't exist", "error", err)
-			return err
+			return ShortcutPlan{}, err
 		}
 		l.Logger.Info("Profile path exists", "profilepath", profilepath)
 	}
 
-	// Join the profile paths by a comma
-	profilePaths := strings.Join(profilepaths, ",")
-	l.Logger.Info("Joined profile paths", "profilePaths", profilePaths)
-
-	// Get the current working directory for the application path
-	cwd, err := os.Getwd()
+	hotKey, err := shelllink.ParseHotKey(shortcut.HotKey)
 	if err != nil {
-		l.Logger.Error("Failed to get working directory", "error", err)
-		return err
+		l.Logger.Error("Invalid hot key", "error", err)
+		return ShortcutPlan{}, err
 	}
-	l.Logger.Info("Current working directory", "cwd", cwd)
-
-	var appName string
-	// Get the name of the application
-	if len(os.Args) > 0 {
-		appName = os.Args[0]
-	} else {
-		appName = "GoPowerShellLauncher"
+	target, err := LauncherExecutable("")
+	if err != nil {
+		return ShortcutPlan{}, err
 	}
-	l.Logger.Info("Application name", "appName", appName)
+	l.Logger.Info("Shortcut target", "target", target)
 
-	profilesCommand := "profiles --path"
-	shellCommand := " --shell"
-	finalCommand := fmt.Sprintf("%s %s %s %s", profilesCommand, profilePaths, shellCommand, shell)
-	l.Logger.Info("Final command", "finalCommand", finalCommand)
+	return ShortcutPlan{
+		Set:         set.Name,
+		Path:        ShortcutFile(shortcut),
+		Target:      target,
+		Arguments:   fmt.Sprintf("launch \"%s\"", set.Name),
+		WorkDir:     workDir,
+		Description: "Shortcut to launch GoPowerShellLauncher with selected profiles",
+		Icon:        shortcutIcon(set),
+		HotKey:      hotKey.String(),
+	}, nil
+}
 
-	_, perr := os.Stat(path)
-	if perr != nil {
-		l.Logger.Error("Destination path doesn't exist", "error", perr)
-		return perr
+// shortcutIcon returns the icon of the set when a shortcut can show it, or the shell executable.
+// Without one the shortcut shows the launcher's icon.
+func shortcutIcon(set Set) string {
+	switch strings.ToLower(filepath.Ext(set.Icon)) {
+	case ".ico", ".exe", ".dll":
+		return set.Icon
 	}
-	l.Logger.Info("Destination path exists", "path", path)
-
-	shortcutPath := fmt.Sprintf("%s%c%s.lnk", path, os.PathSeparator, name)
-	createShortcutCommand := fmt.Sprintf(
-		"$ws = New-Object -ComObject WScript.Shell; "+
-			"$s = $ws.CreateShortcut('%s'); "+
-			"$s.TargetPath = '%s'; "+
-			"$s.Arguments = '%s'; "+
-			"$s.Description = 'Shortcut to launch GoPowerShellLauncher with selected profiles'; "+
-			"$s.Save()",
-		shortcutPath,
-		appName,
-		finalCommand,
-	)
-	l.Logger.Info("Create shortcut command", "createShortcutCommand", createShortcutCommand)
-
-	encodedCommand, encodeerr := EncodeCommand(createShortcutCommand)
-	if encodeerr != nil {
-		l.Logger.Error("Failed to encode command", "error", encodeerr)
-		return encodeerr
+	if shell, err := FindShell(set.Shell); err == nil && strings.EqualFold(filepath.Ext(shell.Path), ".exe") {
+		return shell.Path
 	}
-	l.Logger.Info("Encoded command", "encodedCommand", encodedCommand)
+	return ""
+}
 
-	launcherr := ExecuteCommandWithPowershell(encodedCommand)
-	if launcherr != nil {
-		l.Logger.Error("Failed to create shortcut", "error", launcherr)
-		return launcherr
+// CreateShortcut writes a shortcut that launches the set, and records it in the configuration.
+func CreateShortcut(set Set, shortcut Shortcut) error {
+	plan, err := PlanShortcut(set, shortcut)
+	if err != nil {
+		return err
+	}
+	l.Logger.Info("Creating shortcut", "plan", plan)
+	hotKey, err := shelllink.ParseHotKey(plan.HotKey)
+	if err != nil {
+		return err
+	}
+	link := shelllink.Link{
+		Target:       plan.Target,
+		Arguments:    plan.Arguments,
+		WorkDir:      plan.WorkDir,
+		Description:  plan.Description,
+		IconLocation: plan.Icon,
+		HotKey:       hotKey,
+		ShowCommand:  shelllink.ShowNormal,
+	}
+	if err := shelllink.WriteFile(plan.Path, link); err != nil {
+		l.Logger.Error("Failed to create shortcut", "error", err)
+		return fmt.Errorf("error writing shortcut %s: %w", plan.Path, err)
 	}
-	l.Logger.Info("Shortcut created successfully", "shortcutPath", shortcutPath)
+	l.Logger.Info("Shortcut created successfully", "shortcutPath", plan.Path)
 
-	return nil
+	shortcut.Set = set.Name
+	shortcut.HotKey = plan.HotKey
+	return AddShortcut(shortcut)
 }
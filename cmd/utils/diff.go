@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type DiffKind int
+
+const (
+	DiffEqual DiffKind = iota
+	DiffRemoved
+	DiffAdded
+)
+
+// DiffLine is a single line of a diff. OldLine and NewLine are 1-based and 0 when the line is not present on that side.
+type DiffLine struct {
+	Kind    DiffKind
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// DiffHunk is a range of Lines containing changes and their surrounding context.
+type DiffHunk struct {
+	Start    int
+	End      int
+	OldStart int
+	OldCount int
+	NewStart int
+	NewCount int
+}
+
+func (h DiffHunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldCount, h.NewStart, h.NewCount)
+}
+
+// SplitLines splits content into lines, ignoring the trailing newline and normalising CRLF.
+func SplitLines(content string) []string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+// maxDiffEdits bounds the edits the Myers search looks for. Its trace grows with the square of the edits, so inputs
+// that differ in more lines are diffed as removing the old lines and adding the new ones.
+const maxDiffEdits = 1000
+
+// DiffLines computes the shortest line diff between a and b using the Myers algorithm, after taking off the lines
+// they start and end with in common. Past maxDiffEdits the differing lines are all removed and added instead.
+func DiffLines(a, b []string) []DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []DiffLine
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, DiffLine{Kind: DiffEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+	oldMiddle, newMiddle := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	middle, ok := myersDiff(oldMiddle, newMiddle, maxDiffEdits)
+	if !ok {
+		middle = nil
+		for i, text := range oldMiddle {
+			middle = append(middle, DiffLine{Kind: DiffRemoved, Text: text, OldLine: i + 1})
+		}
+		for i, text := range newMiddle {
+			middle = append(middle, DiffLine{Kind: DiffAdded, Text: text, NewLine: i + 1})
+		}
+	}
+	for _, line := range middle {
+		if line.OldLine > 0 {
+			line.OldLine += prefix
+		}
+		if line.NewLine > 0 {
+			line.NewLine += prefix
+		}
+		lines = append(lines, line)
+	}
+	for i := len(a) - suffix; i < len(a); i++ {
+		lines = append(lines, DiffLine{Kind: DiffEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1 - len(a) + len(b)})
+	}
+	return lines
+}
+
+// myersDiff returns the shortest diff of a and b, or false when it needs more than maxEdits edits.
+func myersDiff(a, b []string, maxEdits int) ([]DiffLine, bool) {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil, true
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] holds v for diagonals -(d+1)..d+1 at the start of step d
+	var trace [][]int
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		if d > maxEdits {
+			return nil, false
+		}
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	var reversed []DiffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		snapshot := trace[d]
+		at := func(k int) int { return snapshot[k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, DiffLine{Kind: DiffEqual, Text: a[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, DiffLine{Kind: DiffAdded, Text: b[y-1], NewLine: y})
+			} else {
+				reversed = append(reversed, DiffLine{Kind: DiffRemoved, Text: a[x-1], OldLine: x})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	lines := make([]DiffLine, len(reversed))
+	for i := range reversed {
+		lines[i] = reversed[len(reversed)-1-i]
+	}
+	return lines, true
+}
+
+// DiffHunks groups the changed lines into hunks with the given number of context lines.
+func DiffHunks(lines []DiffLine, context int) []DiffHunk {
+	var hunks []DiffHunk
+	for i := 0; i < len(lines); i++ {
+		if lines[i].Kind == DiffEqual {
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		// extend the hunk while the next change is within reach of the context
+		for j := i; j < len(lines); j++ {
+			if lines[j].Kind != DiffEqual {
+				end = j
+				continue
+			}
+			if j-end > 2*context {
+				break
+			}
+		}
+		end += context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+		if len(hunks) > 0 && start < hunks[len(hunks)-1].End {
+			start = hunks[len(hunks)-1].End
+		}
+		hunks = append(hunks, newDiffHunk(lines, start, end))
+		i = end - 1
+	}
+	return hunks
+}
+
+func newDiffHunk(lines []DiffLine, start, end int) DiffHunk {
+	h := DiffHunk{Start: start, End: end}
+	for _, line := range lines[start:end] {
+		if line.Kind != DiffAdded {
+			if h.OldStart == 0 {
+				h.OldStart = line.OldLine
+			}
+			h.OldCount++
+		}
+		if line.Kind != DiffRemoved {
+			if h.NewStart == 0 {
+				h.NewStart = line.NewLine
+			}
+			h.NewCount++
+		}
+	}
+	// a side without lines starts after the line before the hunk, as in unified diffs
+	for _, line := range slices.Backward(lines[:start]) {
+		if h.OldCount == 0 && line.OldLine > 0 && h.OldStart == 0 {
+			h.OldStart = line.OldLine
+		}
+		if h.NewCount == 0 && line.NewLine > 0 && h.NewStart == 0 {
+			h.NewStart = line.NewLine
+		}
+	}
+	return h
+}
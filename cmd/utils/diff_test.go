@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// reconstruct returns the old and new lines of a diff.
+func reconstruct(lines []DiffLine) ([]string, []string) {
+	var a, b []string
+	for _, line := range lines {
+		if line.Kind != DiffAdded {
+			a = append(a, line.Text)
+		}
+		if line.Kind != DiffRemoved {
+			b = append(b, line.Text)
+		}
+	}
+	return a, b
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       string
+		b       string
+		changes int
+	}{
+		{"both empty", "", "", 0},
+		{"equal", "a\nb\nc", "a\nb\nc", 0},
+		{"from empty", "", "a\nb", 2},
+		{"to empty", "a\nb", "", 2},
+		{"added in the middle", "a\nc", "a\nb\nc", 1},
+		{"removed at the start", "a\nb\nc", "b\nc", 1},
+		{"changed line", "a\nb\nc", "a\nB\nc", 2},
+		{"moved line", "a\nb\nc\nd", "b\nc\nd\na", 2},
+		{"repeated lines", "x\nx\ny\nx", "x\ny\nx\nx", 2},
+		{"all different", "a\nb", "c\nd\ne", 5},
+		{"crlf", "a\r\nb\r\n", "a\nb\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := SplitLines(tt.a), SplitLines(tt.b)
+			lines := DiffLines(a, b)
+			gotA, gotB := reconstruct(lines)
+			if !reflect.DeepEqual(gotA, a) || !reflect.DeepEqual(gotB, b) {
+				t.Fatalf("diff reconstructs %q and %q, want %q and %q", gotA, gotB, a, b)
+			}
+			changes := 0
+			oldLine, newLine := 0, 0
+			for _, line := range lines {
+				if line.Kind != DiffEqual {
+					changes++
+				}
+				if line.Kind != DiffAdded {
+					oldLine++
+					if line.OldLine != oldLine {
+						t.Errorf("%q has old line %d, want %d", line.Text, line.OldLine, oldLine)
+					}
+				} else if line.OldLine != 0 {
+					t.Errorf("added %q has old line %d, want 0", line.Text, line.OldLine)
+				}
+				if line.Kind != DiffRemoved {
+					newLine++
+					if line.NewLine != newLine {
+						t.Errorf("%q has new line %d, want %d", line.Text, line.NewLine, newLine)
+					}
+				} else if line.NewLine != 0 {
+					t.Errorf("removed %q has new line %d, want 0", line.Text, line.NewLine)
+				}
+			}
+			// the diff is the shortest one
+			if changes != tt.changes {
+				t.Errorf("%d changed lines, want %d", changes, tt.changes)
+			}
+		})
+	}
+}
+
+func TestDiffHunks(t *testing.T) {
+	numbered := func(from, to int, changed map[int]string) string {
+		var lines []string
+		for i := from; i <= to; i++ {
+			line := string(rune('a' + i - 1))
+			if text, ok := changed[i]; ok {
+				line = text
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	tests := []struct {
+		name    string
+		a       string
+		b       string
+		context int
+		headers []string
+	}{
+		{"equal", "a\nb", "a\nb", 3, nil},
+		{"one change", numbered(1, 10, nil), numbered(1, 10, map[int]string{5: "E"}), 3, []string{"@@ -2,7 +2,7 @@"}},
+		{"change at the start", numbered(1, 10, nil), numbered(1, 10, map[int]string{1: "A"}), 3, []string{"@@ -1,4 +1,4 @@"}},
+		{"change at the end", numbered(1, 10, nil), numbered(1, 10, map[int]string{10: "J"}), 3, []string{"@@ -7,4 +7,4 @@"}},
+		{"nearby changes merge", numbered(1, 20, nil), numbered(1, 20, map[int]string{5: "E", 11: "K"}), 3, []string{"@@ -2,13 +2,13 @@"}},
+		{"distant changes split", numbered(1, 20, nil), numbered(1, 20, map[int]string{3: "C", 15: "O"}), 3, []string{"@@ -1,6 +1,6 @@", "@@ -12,7 +12,7 @@"}},
+		{"added line", numbered(1, 10, nil), numbered(1, 10, map[int]string{5: "e\nnew"}), 1, []string{"@@ -5,2 +5,3 @@"}},
+		{"removed line", numbered(1, 10, nil), numbered(1, 10, map[int]string{5: ""}), 1, []string{"@@ -4,3 +4,2 @@"}},
+		{"added without context", numbered(1, 10, nil), numbered(1, 10, map[int]string{5: "e\nnew"}), 0, []string{"@@ -5,0 +6,1 @@"}},
+		{"removed without context", numbered(1, 10, nil), numbered(1, 10, map[int]string{5: ""}), 0, []string{"@@ -5,1 +4,0 @@"}},
+		{"from empty", "", "a\nb", 3, []string{"@@ -0,0 +1,2 @@"}},
+		{"to empty", "a\nb", "", 3, []string{"@@ -1,2 +0,0 @@"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := DiffLines(SplitLines(tt.a), SplitLines(tt.b))
+			hunks := DiffHunks(lines, tt.context)
+			var headers []string
+			for i, hunk := range hunks {
+				headers = append(headers, hunk.Header())
+				if i > 0 && hunk.Start < hunks[i-1].End {
+					t.Errorf("hunk %d starts at %d inside the previous one ending at %d", i, hunk.Start, hunks[i-1].End)
+				}
+			}
+			if !reflect.DeepEqual(headers, tt.headers) {
+				t.Errorf("headers = %q, want %q", headers, tt.headers)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLargeInputs(t *testing.T) {
+	generate := func(n int, line func(i int) string) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = line(i)
+		}
+		return lines
+	}
+	base := generate(5000, func(i int) string { return fmt.Sprintf("line %d", i) })
+	oneChange := append([]string{}, base...)
+	oneChange[2500] = "changed"
+	unrelated := generate(5000, func(i int) string { return fmt.Sprintf("other %d", i) })
+	everyOther := generate(5000, func(i int) string {
+		if i%2 == 0 {
+			return "changed"
+		}
+		return base[i]
+	})
+	tests := []struct {
+		name    string
+		b       []string
+		changes int
+	}{
+		{"one change", oneChange, 2},
+		{"unrelated", unrelated, 10000},
+		// more edits than the search allows, the lines between the common last line and the start are removed and added
+		{"every other line", everyOther, 2 * 4999},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			lines := DiffLines(base, tt.b)
+			runtime.ReadMemStats(&after)
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+				t.Errorf("allocated %d MB", allocated>>20)
+			}
+			gotA, gotB := reconstruct(lines)
+			if !reflect.DeepEqual(gotA, base) || !reflect.DeepEqual(gotB, tt.b) {
+				t.Fatal("the diff does not reconstruct the inputs")
+			}
+			changes := 0
+			for _, line := range lines {
+				if line.Kind != DiffEqual {
+					changes++
+				}
+			}
+			if changes != tt.changes {
+				t.Errorf("%d changed lines, want %d", changes, tt.changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+var envReferencePattern = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_()]*)%|\$\{env:([A-Za-z_][A-Za-z0-9_()]*)\}|\$env:([A-Za-z_][A-Za-z0-9_]*)`)
+
+// ParseEnvHeaders parses the NAME=value pairs declared in ### ENV:NAME=value:ENV ### headers.
+func ParseEnvHeaders(headers []string) ([]string, error) {
+	var env []string
+	var invalid []string
+	for _, header := range headers {
+		pair := strings.TrimSpace(header)
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			invalid = append(invalid, pair)
+			continue
+		}
+		env = append(env, name+"="+strings.TrimSpace(value))
+	}
+	if len(invalid) > 0 {
+		return env, fmt.Errorf("invalid environment variable declarations: %s", strings.Join(invalid, ", "))
+	}
+	return env, nil
+}
+
+// ExpandEnvValue replaces %VAR%, $env:VAR and ${env:VAR} references in value using lookup.
+// References that cannot be resolved are left untouched.
+func ExpandEnvValue(value string, lookup func(string) (string, bool)) string {
+	return envReferencePattern.ReplaceAllStringFunc(value, func(ref string) string {
+		matches := envReferencePattern.FindStringSubmatch(ref)
+		name := matches[1] + matches[2] + matches[3]
+		if resolved, ok := lookup(name); ok {
+			return resolved
+		}
+		return ref
+	})
+}
+
+// MergeProfileEnv merges the environment variables declared by the profiles in order.
+// Values are expanded against the variables merged so far and then the current process environment.
+// A variable redeclared with a different value keeps the last value and produces a warning.
+func MergeProfileEnv(profilePaths []string) ([]string, []string) {
+	l.Logger.Info("Merging profile environment variables", "profiles", profilePaths)
+	var names []string
+	values := make(map[string]string)
+	sources := make(map[string]string)
+	var warnings []string
+
+	lookup := func(name string) (string, bool) {
+		if value, ok := values[envKey(name)]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+
+	for _, path := range profilePaths {
+		profile, err := GetProfileProperties(path)
+		if err != nil {
+			l.Logger.Warn("Failed to get profile properties", "path", path, "error", err)
+			continue
+		}
+		for _, pair := range profile.Env {
+			name, value, _ := strings.Cut(pair, "=")
+			value = ExpandEnvValue(value, lookup)
+			key := envKey(name)
+			if existing, ok := values[key]; ok {
+				if existing != value {
+					warning := fmt.Sprintf("%s is set to %q by %s and %q by %s, using %q", name, existing, sources[key], value, profile.GetName(), value)
+					l.Logger.Warn("Conflicting environment variable", "name", name, "previous", existing, "value", value, "profile", path)
+					warnings = append(warnings, warning)
+				}
+			} else {
+				names = append(names, name)
+			}
+			values[key] = value
+			sources[key] = profile.GetName()
+		}
+	}
+
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		env = append(env, name+"="+values[envKey(name)])
+	}
+	l.Logger.Debug("Merged profile environment", "env", env)
+	return env, warnings
+}
+
+// FormatEnvSummary renders the merged environment for the launch summary.
+func FormatEnvSummary(env []string) string {
+	if len(env) == 0 {
+		return "Environment: none"
+	}
+	var b strings.Builder
+	b.WriteString("Environment:")
+	for _, pair := range env {
+		b.WriteString("\n  " + pair)
+	}
+	return b.String()
+}
+
+// Environment variable names are case insensitive on Windows.
+func envKey(name string) string {
+	if runtime.GOOS == "windows" {
+		return strings.ToUpper(name)
+	}
+	return name
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+// ExportEntry is a set, or a shortcut to a set, written to another application's configuration.
+type ExportEntry struct {
+	ID      string
+	Name    string
+	Set     Set
+	WorkDir string
+}
+
+// ExportEntries returns an entry for each set and for each shortcut not named after its set.
+// Shortcuts written before sets existed are skipped with a warning.
+func ExportEntries() ([]ExportEntry, []string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	var entries []ExportEntry
+	var warnings []string
+	for _, set := range config.Sets {
+		entries = append(entries, newExportEntry("set", set.Name, set))
+	}
+	for _, shortcut := range config.Shortcuts {
+		if shortcut.Set == "" {
+			warnings = append(warnings, fmt.Sprintf("shortcut %s has no set, recreate it to export it", shortcut.Name))
+			continue
+		}
+		if strings.EqualFold(shortcut.Name, shortcut.Set) || exportEntryExists(entries, shortcut.Name) {
+			continue
+		}
+		set, err := FindSet(shortcut.Set)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("shortcut %s: %v", shortcut.Name, err))
+			continue
+		}
+		entries = append(entries, newExportEntry("shortcut", shortcut.Name, set))
+	}
+	l.Logger.Info("Export entries", "count", len(entries), "warnings", warnings)
+	return entries, warnings, nil
+}
+
+func newExportEntry(kind string, name string, set Set) ExportEntry {
+	workDir := set.WorkDir
+	if workDir != "" {
+		if resolved, err := ExpandPath(workDir); err == nil {
+			workDir = resolved
+		}
+	} else {
+		workDir, _ = MergeProfileWorkDir(set.Profiles)
+	}
+	return ExportEntry{ID: exportID(kind, name), Name: name, Set: set, WorkDir: workDir}
+}
+
+func exportEntryExists(entries []ExportEntry, name string) bool {
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// LauncherExecutable returns the launcher path written into exports: the override, the export.executable
+// setting, or the running executable.
+func LauncherExecutable(override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+	if config, err := LoadConfig(); err == nil && config.Export.Executable != "" {
+		return config.Export.Executable, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("error getting executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe, nil
+}
+
+// LaunchArgs returns the launcher arguments that run the set inline in the terminal of the calling application.
+func LaunchArgs(set Set) []string {
+	return []string{"launch", set.Name, "--inline"}
+}
+
+// windowsCommandLine joins arguments the way Windows programs split them, quoting those with spaces.
+func windowsCommandLine(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		if arg == "" || strings.ContainsAny(arg, " \t\"") {
+			arg = `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+// VSCodeTerminalProfile is an entry of terminal.integrated.profiles.<os> in VS Code's settings.
+type VSCodeTerminalProfile struct {
+	Name         string   `json:"-"`
+	Path         string   `json:"path"`
+	Args         []string `json:"args"`
+	Icon         string   `json:"icon,omitempty"`
+	OverrideName bool     `json:"overrideName"`
+}
+
+// VSCodePlatform returns the settings suffix VS Code uses for the operating system, such as linux.
+func VSCodePlatform(goos string) (string, error) {
+	switch goos {
+	case "windows", "linux":
+		return goos, nil
+	case "darwin", "osx":
+		return "osx", nil
+	}
+	return "", fmt.Errorf("unsupported platform %q, use windows, linux or osx", goos)
+}
+
+// VSCodeSettingsPath returns the user settings.json of VS Code.
+func VSCodeSettingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting user config directory: %w", err)
+	}
+	return filepath.Join(dir, "Code", "User", "settings.json"), nil
+}
+
+// VSCodeTerminalProfiles returns a terminal profile for each exported entry, running the set inline.
+func VSCodeTerminalProfiles(executable string) ([]VSCodeTerminalProfile, []string, error) {
+	entries, warnings, err := ExportEntries()
+	if err != nil {
+		return nil, nil, err
+	}
+	profiles := make([]VSCodeTerminalProfile, len(entries))
+	for i, entry := range entries {
+		icon := "terminal-powershell"
+		if shell, err := FindShell(entry.Set.Shell); err == nil {
+			switch shell.Kind {
+			case launcher.KindBash:
+				icon = "terminal-bash"
+			case launcher.KindZsh:
+				icon = "terminal-linux"
+			}
+		}
+		profiles[i] = VSCodeTerminalProfile{
+			Name:         entry.Name,
+			Path:         executable,
+			Args:         LaunchArgs(entry.Set),
+			Icon:         icon,
+			OverrideName: true,
+		}
+	}
+	return profiles, warnings, nil
+}
+
+// WriteVSCodeSettings merges the profiles into terminal.integrated.profiles.<platform> of the settings file at path.
+// Other settings, comments and keys added to the entries by hand are kept. Entries that run the executable's
+// launch command but were not generated this time are removed.
+func WriteVSCodeSettings(path string, platform string, executable string, profiles []VSCodeTerminalProfile) (ExportResult, error) {
+	result := ExportResult{Path: path}
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return result, fmt.Errorf("error reading settings %s: %w", path, err)
+	}
+	src := string(content)
+	if strings.TrimSpace(src) == "" {
+		src = "{}\n"
+	}
+	settingKey := "terminal.integrated.profiles." + platform
+
+	// every edit moves the offsets after it, so the document is parsed again before each one
+	profilesObject := func() (jsoncObject, jsoncObject, bool, error) {
+		root, err := parseJSONCRoot(src)
+		if err != nil {
+			return root, jsoncObject{}, false, fmt.Errorf("error parsing settings %s: %w", path, err)
+		}
+		i, ok := root.find(settingKey)
+		if !ok {
+			return root, jsoncObject{}, false, nil
+		}
+		object, err := parseJSONCObject(src, root.Members[i].ValueStart)
+		if err != nil {
+			return root, jsoncObject{}, false, fmt.Errorf("error parsing %s in %s: %w", settingKey, path, err)
+		}
+		return root, object, true, nil
+	}
+
+	root, object, ok, err := profilesObject()
+	if err != nil {
+		return result, err
+	}
+	unit := jsoncIndentUnit(src, root)
+	if !ok {
+		if src, err = setJSONCMember(src, root, settingKey, map[string]interface{}{}, unit); err != nil {
+			return result, err
+		}
+	}
+
+	generated := map[string]bool{}
+	for _, profile := range profiles {
+		generated[profile.Name] = true
+		_, object, _, err := profilesObject()
+		if err != nil {
+			return result, err
+		}
+		value, changed, err := mergeVSCodeProfile(src, object, profile)
+		if err != nil {
+			return result, err
+		}
+		_, exists := object.find(profile.Name)
+		switch {
+		case !exists:
+			result.Added++
+		case changed:
+			result.Updated++
+		default:
+			continue
+		}
+		if src, err = setJSONCMember(src, object, profile.Name, value, unit); err != nil {
+			return result, err
+		}
+	}
+
+	for {
+		_, object, _, err = profilesObject()
+		if err != nil {
+			return result, err
+		}
+		stale := -1
+		for i, member := range object.Members {
+			if !generated[member.Key] && isLauncherVSCodeProfile(src[member.ValueStart:member.ValueEnd], executable) {
+				stale = i
+				break
+			}
+		}
+		if stale < 0 {
+			break
+		}
+		l.Logger.Info("Removing terminal profile of a removed set", "name", object.Members[stale].Key)
+		src = removeJSONCMember(src, object, stale)
+		result.Removed++
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return result, fmt.Errorf("error creating settings directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		return result, fmt.Errorf("error writing settings %s: %w", path, err)
+	}
+	l.Logger.Info("VS Code settings written", "result", result.String())
+	return result, nil
+}
+
+// mergeVSCodeProfile returns the entry for the profile, keeping the other keys of an existing entry,
+// and whether it differs from the existing one.
+func mergeVSCodeProfile(src string, object jsoncObject, profile VSCodeTerminalProfile) (map[string]interface{}, bool, error) {
+	encoded, err := json.Marshal(profile)
+	if err != nil {
+		return nil, false, err
+	}
+	value := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return nil, false, err
+	}
+	i, ok := object.find(profile.Name)
+	if !ok {
+		return value, true, nil
+	}
+	member := object.Members[i]
+	existing := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(stripJSONC(src[member.ValueStart:member.ValueEnd])), &existing); err != nil {
+		l.Logger.Warn("Replacing unreadable terminal profile", "name", profile.Name, "error", err)
+		return value, true, nil
+	}
+	merged := map[string]interface{}{}
+	for key, v := range existing {
+		merged[key] = v
+	}
+	for key, v := range value {
+		merged[key] = v
+	}
+	return merged, !reflect.DeepEqual(existing, merged), nil
+}
+
+// isLauncherVSCodeProfile reports whether a terminal profile runs the launch command of the executable.
+func isLauncherVSCodeProfile(value string, executable string) bool {
+	var profile struct {
+		Path string   `json:"path"`
+		Args []string `json:"args"`
+	}
+	if err := json.Unmarshal([]byte(stripJSONC(value)), &profile); err != nil {
+		return false
+	}
+	return samePath(profile.Path, executable) && len(profile.Args) > 0 && profile.Args[0] == "launch"
+}
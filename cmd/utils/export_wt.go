@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+// WindowsTerminalProfile is a profile in a Windows Terminal JSON fragment.
+type WindowsTerminalProfile struct {
+	GUID              string `json:"guid"`
+	Name              string `json:"name"`
+	Commandline       string `json:"commandline"`
+	StartingDirectory string `json:"startingDirectory,omitempty"`
+	Icon              string `json:"icon,omitempty"`
+	ColorScheme       string `json:"colorScheme,omitempty"`
+}
+
+// ExportResult counts the entries changed by an export.
+type ExportResult struct {
+	Path    string
+	Added   int
+	Updated int
+	Removed int
+}
+
+func (r ExportResult) String() string {
+	return fmt.Sprintf("%s: %d added, %d updated, %d removed", r.Path, r.Added, r.Updated, r.Removed)
+}
+
+// WindowsTerminalFragmentPath returns the fragment file to write, the export.wt.path setting or the
+// fragments folder Windows Terminal reads on Windows.
+func WindowsTerminalFragmentPath() (string, error) {
+	if config, err := LoadConfig(); err == nil && config.Export.WindowsTerminal.Path != "" {
+		return ExpandPath(config.Export.WindowsTerminal.Path)
+	}
+	if runtime.GOOS != "windows" {
+		return "", fmt.Errorf("no fragment path, set export.wt.path or pass --output")
+	}
+	localAppData, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting local app data directory: %w", err)
+	}
+	return filepath.Join(localAppData, "Microsoft", "Windows Terminal", "Fragments", "GoPowerShellLauncher", "launcher.json"), nil
+}
+
+// WindowsTerminalProfiles returns a Windows Terminal profile for each exported entry.
+func WindowsTerminalProfiles(executable string) ([]WindowsTerminalProfile, []string, error) {
+	entries, warnings, err := ExportEntries()
+	if err != nil {
+		return nil, nil, err
+	}
+	config, _ := LoadConfig()
+	profiles := make([]WindowsTerminalProfile, len(entries))
+	for i, entry := range entries {
+		profile := WindowsTerminalProfile{
+			GUID:              "{" + entry.ID + "}",
+			Name:              entry.Name,
+			Commandline:       windowsCommandLine(append([]string{executable}, LaunchArgs(entry.Set)...)),
+			StartingDirectory: entry.WorkDir,
+			Icon:              entry.Set.Icon,
+			ColorScheme:       entry.Set.ColorScheme,
+		}
+		if profile.Icon == "" {
+			// Windows Terminal takes the icon of an executable, only useful when exporting on Windows
+			if shell, err := FindShell(entry.Set.Shell); err == nil && strings.EqualFold(filepath.Ext(shell.Path), ".exe") {
+				profile.Icon = shell.Path
+			}
+		}
+		if profile.ColorScheme == "" && config != nil {
+			profile.ColorScheme = config.Export.WindowsTerminal.ColorScheme
+		}
+		profiles[i] = profile
+	}
+	return profiles, warnings, nil
+}
+
+// WriteWindowsTerminalFragment merges the profiles into the fragment at path. Profiles are matched on their GUID
+// and updated in place, keeping keys added by hand. Profiles of removed sets, those that run the executable but
+// were not generated this time, are dropped.
+func WriteWindowsTerminalFragment(path string, executable string, profiles []WindowsTerminalProfile) (ExportResult, error) {
+	result := ExportResult{Path: path}
+	fragment := map[string]interface{}{}
+	if content, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(content, &fragment); err != nil {
+			return result, fmt.Errorf("error reading fragment %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return result, fmt.Errorf("error reading fragment %s: %w", path, err)
+	}
+	existing, _ := fragment["profiles"].([]interface{})
+
+	generated := map[string]WindowsTerminalProfile{}
+	for _, profile := range profiles {
+		generated[strings.ToLower(profile.GUID)] = profile
+	}
+	launcherPrefix := strings.ToLower(windowsCommandLine([]string{executable}) + " launch ")
+	merged := []interface{}{}
+	for _, item := range existing {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			merged = append(merged, item)
+			continue
+		}
+		guid, _ := entry["guid"].(string)
+		profile, ok := generated[strings.ToLower(guid)]
+		if !ok {
+			commandline, _ := entry["commandline"].(string)
+			if strings.HasPrefix(strings.ToLower(commandline), launcherPrefix) {
+				l.Logger.Info("Removing profile of a removed set", "name", entry["name"], "guid", guid)
+				result.Removed++
+				continue
+			}
+			merged = append(merged, entry)
+			continue
+		}
+		delete(generated, strings.ToLower(guid))
+		if applyWindowsTerminalProfile(entry, profile) {
+			result.Updated++
+		}
+		merged = append(merged, entry)
+	}
+	for _, profile := range profiles {
+		if _, ok := generated[strings.ToLower(profile.GUID)]; !ok {
+			continue
+		}
+		entry := map[string]interface{}{}
+		applyWindowsTerminalProfile(entry, profile)
+		merged = append(merged, entry)
+		result.Added++
+	}
+	fragment["profiles"] = merged
+
+	content, err := json.MarshalIndent(fragment, "", "    ")
+	if err != nil {
+		return result, fmt.Errorf("error encoding fragment: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return result, fmt.Errorf("error creating fragment directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		return result, fmt.Errorf("error writing fragment %s: %w", path, err)
+	}
+	l.Logger.Info("Windows Terminal fragment written", "result", result.String())
+	return result, nil
+}
+
+// applyWindowsTerminalProfile sets the generated keys on a fragment entry and reports whether any changed.
+// Empty values leave the key alone, so a colour scheme or icon set by hand is kept.
+func applyWindowsTerminalProfile(entry map[string]interface{}, profile WindowsTerminalProfile) bool {
+	values := map[string]string{
+		"guid":              profile.GUID,
+		"name":              profile.Name,
+		"commandline":       profile.Commandline,
+		"startingDirectory": profile.StartingDirectory,
+		"icon":              profile.Icon,
+		"colorScheme":       profile.ColorScheme,
+	}
+	changed := false
+	for key, value := range values {
+		if value == "" {
+			continue
+		}
+		if entry[key] != value {
+			entry[key] = value
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+// historyLimit is the number of entries kept in the history file.
+const historyLimit = 200
+
+// HistoryEntry is a single launch, stored as one JSON line in the history file.
+type HistoryEntry struct {
+	Time     time.Time `json:"time"`
+	Name     string    `json:"name,omitempty"`
+	Shell    string    `json:"shell"`
+	Profiles []string  `json:"profiles"`
+	Hashes   []string  `json:"hashes"`
+	Mode     string    `json:"mode"`
+	WorkDir  string    `json:"workDir,omitempty"`
+	Args     []string  `json:"args"` // null for the launcher defaults, empty for a launch without arguments
+	PID      int       `json:"pid,omitempty"`
+	Waited   bool      `json:"waited,omitempty"`
+	ExitCode int       `json:"exitCode,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Summary describes the entry in a single line.
+func (e HistoryEntry) Summary() string {
+	names := make([]string, len(e.Profiles))
+	for i, profile := range e.Profiles {
+		names[i] = filepath.Base(profile)
+	}
+	return fmt.Sprintf("%s %s (%s): %s", e.Time.Local().Format("2006-01-02 15:04"), e.Shell, e.Mode, strings.Join(names, ", "))
+}
+
+// Outcome describes how the launch went.
+func (e HistoryEntry) Outcome() string {
+	switch {
+	case e.Error != "":
+		return "failed: " + e.Error
+	case e.Waited && e.ExitCode >= 0:
+		return fmt.Sprintf("PID %d, exit code %d", e.PID, e.ExitCode)
+	case e.PID > 0:
+		return fmt.Sprintf("PID %d", e.PID)
+	}
+	return "started"
+}
+
+// ChangedProfiles returns the profiles that were modified or removed since the launch.
+func (e HistoryEntry) ChangedProfiles() []string {
+	var changed []string
+	for i, profile := range e.Profiles {
+		hash, err := hashProfile(profile)
+		if err != nil || i >= len(e.Hashes) || hash != e.Hashes[i] {
+			changed = append(changed, profile)
+		}
+	}
+	return changed
+}
+
+var historyMu sync.Mutex
+
+func HistoryPath() string {
+	return filepath.Join(UserConfigDir, "history.jsonl")
+}
+
+func hashProfile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// NewHistoryEntry records a launch request and its result.
+func NewHistoryEntry(req launcher.Request, result launcher.Result, launchErr error) HistoryEntry {
+	entry := HistoryEntry{
+		Time:     result.Started,
+		Name:     req.Name,
+		Shell:    req.Shell,
+		Profiles: req.Profiles,
+		Hashes:   make([]string, len(req.Profiles)),
+		Mode:     string(req.Mode),
+		WorkDir:  req.WorkDir,
+		Args:     req.Args,
+		PID:      result.PID,
+		Waited:   result.Waited,
+		ExitCode: result.ExitCode,
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	// a shell that ran and exited with an error is recorded by its exit code
+	if launchErr != nil && !result.Waited {
+		entry.Error = launchErr.Error()
+	}
+	for i, profile := range req.Profiles {
+		hash, err := hashProfile(profile)
+		if err != nil {
+			l.Logger.Warn("Failed to hash profile", "path", profile, "error", err)
+			continue
+		}
+		entry.Hashes[i] = hash
+	}
+	return entry
+}
+
+// AppendHistory adds an entry to the history file, dropping the oldest entries over the limit.
+func AppendHistory(entry HistoryEntry) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	entries, err := readHistory()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	if len(entries) > historyLimit {
+		entries = entries[len(entries)-historyLimit:]
+	}
+	return writeHistory(entries)
+}
+
+// LoadHistory returns the history, newest first.
+func LoadHistory() ([]HistoryEntry, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	entries, err := readHistory()
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+func ClearHistory() error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	err := os.Remove(HistoryPath())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing history: %w", err)
+	}
+	return nil
+}
+
+// readHistory returns the entries oldest first, lines that fail to decode are skipped.
+func readHistory() ([]HistoryEntry, error) {
+	file, err := os.Open(HistoryPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening history: %w", err)
+	}
+	defer file.Close()
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			l.Logger.Warn("Skipping invalid history entry", "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history: %w", err)
+	}
+	return entries, nil
+}
+
+// writeHistory replaces the history file through a temporary file so a crash cannot truncate it.
+func writeHistory(entries []HistoryEntry) error {
+	if err := os.MkdirAll(filepath.Dir(HistoryPath()), 0o755); err != nil {
+		return fmt.Errorf("error creating history directory: %w", err)
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("error encoding history entry: %w", err)
+		}
+		b.Write(line)
+		b.WriteString("\n")
+	}
+	tmp := HistoryPath() + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("error writing history: %w", err)
+	}
+	if err := os.Rename(tmp, HistoryPath()); err != nil {
+		return fmt.Errorf("error replacing history: %w", err)
+	}
+	return nil
+}
+
+// RelaunchRequest rebuilds the launch request of a history entry from the current profile contents.
+// The warnings include profiles that changed since the original launch.
+func RelaunchRequest(entry HistoryEntry) (launcher.Request, []string, error) {
+	shell, err := FindShell(entry.Shell)
+	if err != nil {
+		return launcher.Request{}, nil, err
+	}
+	for _, profile := range entry.Profiles {
+		if _, err := os.Stat(profile); err != nil {
+			return launcher.Request{}, nil, fmt.Errorf("profile no longer exists: %s", profile)
+		}
+	}
+	var warnings []string
+	for _, profile := range entry.ChangedProfiles() {
+		warnings = append(warnings, fmt.Sprintf("%s changed since it was launched", filepath.Base(profile)))
+	}
+	env, envWarnings := MergeProfileEnv(entry.Profiles)
+	warnings = append(warnings, envWarnings...)
+	req := NewLaunchRequest(shell, entry.Profiles, env, entry.WorkDir, entry.Args)
+	req.Name = entry.Name
+	if entry.Mode != "" {
+		req.Mode = launcher.Mode(entry.Mode)
+	}
+	return req, warnings, nil
+}
+
+// HistoryLauncher records every launch of the wrapped launcher in the history file.
+type HistoryLauncher struct {
+	launcher.Launcher
+}
+
+func NewHistoryLauncher(next launcher.Launcher) *HistoryLauncher {
+	return &HistoryLauncher{Launcher: next}
+}
+
+func (h *HistoryLauncher) Launch(req launcher.Request) (launcher.Result, error) {
+	result, err := h.Launcher.Launch(req)
+	if historyErr := AppendHistory(NewHistoryEntry(req, result, err)); historyErr != nil {
+		l.Logger.Error("Failed to record launch history", "Error", historyErr)
+	}
+	return result, err
+}
+
+var _ launcher.Launcher = (*HistoryLauncher)(nil)
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+)
+
+func TestRelaunchKeepsEmptyArgs(t *testing.T) {
+	dir := t.TempDir()
+	profile := writeTestProfile(t, dir, "Dev.Profile.sh", "SHELL:testbash:SHELL")
+	useTestConfig(t, testShellConfig(writeTestShell(t, dir)))
+	previous := UserConfigDir
+	UserConfigDir = dir
+	t.Cleanup(func() { UserConfigDir = previous })
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"defaults", nil},
+		{"no arguments", []string{}},
+		{"arguments", []string{"--login"}},
+	}
+	for _, tt := range tests {
+		req := launcher.Request{Name: tt.name, Shell: "testbash", Kind: "bash", Profiles: []string{profile}, Args: tt.args}
+		if err := AppendHistory(NewHistoryEntry(req, launcher.Result{}, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := LoadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(tests) {
+		t.Fatalf("%d history entries, want %d", len(entries), len(tests))
+	}
+	for i, tt := range tests {
+		entry := entries[len(entries)-1-i]
+		req, _, err := RelaunchRequest(entry)
+		if err != nil {
+			t.Fatalf("RelaunchRequest(%s): %v", tt.name, err)
+		}
+		if (req.Args == nil) != (tt.args == nil) || !slices.Equal(req.Args, tt.args) {
+			t.Errorf("relaunch of %s has args %#v, want %#v", tt.name, req.Args, tt.args)
+		}
+	}
+}
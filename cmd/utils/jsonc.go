@@ -0,0 +1,314 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The JSONC helpers edit JSON with comments and trailing commas, such as VS Code's settings.json, in place.
+// Only the spans of the changed members are rewritten, so comments and formatting elsewhere are kept.
+
+// jsoncMember is a member of a JSONC object, as offsets into the source.
+type jsoncMember struct {
+	Key        string
+	Start      int
+	ValueStart int
+	ValueEnd   int
+	// End is after the comma following the value, ValueEnd when there is none.
+	End int
+}
+
+// jsoncObject is an object of a JSONC source, Open and Close are the offsets of its braces.
+type jsoncObject struct {
+	Open    int
+	Close   int
+	Members []jsoncMember
+}
+
+func (o jsoncObject) find(key string) (int, bool) {
+	for i, member := range o.Members {
+		if member.Key == key {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// skipJSONCSpace returns the offset of the next token after whitespace and comments.
+func skipJSONCSpace(src string, pos int) int {
+	for pos < len(src) {
+		switch {
+		case strings.ContainsRune(" \t\r\n", rune(src[pos])):
+			pos++
+		case strings.HasPrefix(src[pos:], "//"):
+			end := strings.IndexByte(src[pos:], '\n')
+			if end < 0 {
+				return len(src)
+			}
+			pos += end + 1
+		case strings.HasPrefix(src[pos:], "/*"):
+			end := strings.Index(src[pos+2:], "*/")
+			if end < 0 {
+				return len(src)
+			}
+			pos += end + 4
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+func scanJSONCString(src string, pos int) (int, error) {
+	for i := pos + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at offset %d", pos)
+}
+
+// scanJSONCValue returns the end of the value starting at pos.
+func scanJSONCValue(src string, pos int) (int, error) {
+	if pos >= len(src) {
+		return 0, fmt.Errorf("unexpected end of input")
+	}
+	switch src[pos] {
+	case '"':
+		return scanJSONCString(src, pos)
+	case '{':
+		object, err := parseJSONCObject(src, pos)
+		if err != nil {
+			return 0, err
+		}
+		return object.Close + 1, nil
+	case '[':
+		pos = skipJSONCSpace(src, pos+1)
+		for pos < len(src) && src[pos] != ']' {
+			end, err := scanJSONCValue(src, pos)
+			if err != nil {
+				return 0, err
+			}
+			pos = skipJSONCSpace(src, end)
+			if pos < len(src) && src[pos] == ',' {
+				pos = skipJSONCSpace(src, pos+1)
+			} else if pos < len(src) && src[pos] != ']' {
+				return 0, fmt.Errorf("expected , or ] at offset %d", pos)
+			}
+		}
+		if pos >= len(src) {
+			return 0, fmt.Errorf("unterminated array")
+		}
+		return pos + 1, nil
+	}
+	end := pos
+	for end < len(src) && !strings.ContainsRune(" \t\r\n,]}/", rune(src[end])) {
+		end++
+	}
+	if end == pos {
+		return 0, fmt.Errorf("unexpected %q at offset %d", src[pos], pos)
+	}
+	return end, nil
+}
+
+// parseJSONCObject parses the object whose opening brace is at pos.
+func parseJSONCObject(src string, pos int) (jsoncObject, error) {
+	if pos >= len(src) || src[pos] != '{' {
+		return jsoncObject{}, fmt.Errorf("expected an object at offset %d", pos)
+	}
+	object := jsoncObject{Open: pos}
+	pos = skipJSONCSpace(src, pos+1)
+	for pos < len(src) && src[pos] != '}' {
+		if src[pos] != '"' {
+			return jsoncObject{}, fmt.Errorf("expected a key at offset %d", pos)
+		}
+		keyEnd, err := scanJSONCString(src, pos)
+		if err != nil {
+			return jsoncObject{}, err
+		}
+		var key string
+		if err := json.Unmarshal([]byte(src[pos:keyEnd]), &key); err != nil {
+			return jsoncObject{}, fmt.Errorf("invalid key at offset %d: %w", pos, err)
+		}
+		member := jsoncMember{Key: key, Start: pos}
+		colon := skipJSONCSpace(src, keyEnd)
+		if colon >= len(src) || src[colon] != ':' {
+			return jsoncObject{}, fmt.Errorf("expected : at offset %d", colon)
+		}
+		member.ValueStart = skipJSONCSpace(src, colon+1)
+		if member.ValueEnd, err = scanJSONCValue(src, member.ValueStart); err != nil {
+			return jsoncObject{}, err
+		}
+		member.End = member.ValueEnd
+		pos = skipJSONCSpace(src, member.ValueEnd)
+		if pos < len(src) && src[pos] == ',' {
+			member.End = pos + 1
+			pos = skipJSONCSpace(src, pos+1)
+		} else if pos < len(src) && src[pos] != '}' {
+			return jsoncObject{}, fmt.Errorf("expected , or } at offset %d", pos)
+		}
+		object.Members = append(object.Members, member)
+	}
+	if pos >= len(src) {
+		return jsoncObject{}, fmt.Errorf("unterminated object")
+	}
+	object.Close = pos
+	return object, nil
+}
+
+// parseJSONCRoot parses the top level object of a JSONC document.
+func parseJSONCRoot(src string) (jsoncObject, error) {
+	return parseJSONCObject(src, skipJSONCSpace(src, 0))
+}
+
+// stripJSONC removes comments and trailing commas so the source can be decoded as JSON.
+func stripJSONC(src string) string {
+	var b strings.Builder
+	for pos := 0; pos < len(src); {
+		switch {
+		case src[pos] == '"':
+			end, err := scanJSONCString(src, pos)
+			if err != nil {
+				end = len(src)
+			}
+			b.WriteString(src[pos:end])
+			pos = end
+		case strings.HasPrefix(src[pos:], "//"), strings.HasPrefix(src[pos:], "/*"):
+			pos = skipJSONCSpace(src, pos)
+			b.WriteByte(' ')
+		case src[pos] == ',':
+			if next := skipJSONCSpace(src, pos+1); next < len(src) && (src[next] == '}' || src[next] == ']') {
+				pos++
+				continue
+			}
+			b.WriteByte(',')
+			pos++
+		default:
+			b.WriteByte(src[pos])
+			pos++
+		}
+	}
+	return b.String()
+}
+
+// lineIndent returns the whitespace at the start of the line containing pos.
+func lineIndent(src string, pos int) string {
+	start := strings.LastIndexByte(src[:pos], '\n') + 1
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return src[start:end]
+}
+
+// jsoncIndentUnit guesses the indentation of the document from its first member, four spaces by default.
+func jsoncIndentUnit(src string, root jsoncObject) string {
+	if len(root.Members) > 0 && strings.Contains(src[root.Open:root.Members[0].Start], "\n") {
+		if indent := lineIndent(src, root.Members[0].Start); indent != "" {
+			return indent
+		}
+	}
+	return "    "
+}
+
+// marshalJSONC encodes a value to be written after a key indented by prefix.
+func marshalJSONC(value interface{}, prefix string, indent string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(prefix, indent)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// memberIndent returns the indentation of the members of an object.
+func memberIndent(src string, object jsoncObject, unit string) string {
+	if len(object.Members) > 0 && strings.Contains(src[object.Open:object.Members[0].Start], "\n") {
+		return lineIndent(src, object.Members[0].Start)
+	}
+	return lineIndent(src, object.Open) + unit
+}
+
+// setJSONCMember replaces the value of key in the object, or adds the member at the end.
+func setJSONCMember(src string, object jsoncObject, key string, value interface{}, unit string) (string, error) {
+	indent := memberIndent(src, object, unit)
+	encoded, err := marshalJSONC(value, indent, unit)
+	if err != nil {
+		return "", err
+	}
+	if i, ok := object.find(key); ok {
+		member := object.Members[i]
+		return src[:member.ValueStart] + encoded + src[member.ValueEnd:], nil
+	}
+	encodedKey, err := marshalJSONC(key, "", "")
+	if err != nil {
+		return "", err
+	}
+	entry := encodedKey + ": " + encoded
+	if len(object.Members) == 0 {
+		// comments in the empty object are kept after the new member
+		inner := strings.TrimRight(src[object.Open+1:object.Close], " \t\r\n")
+		if strings.TrimSpace(inner) == "" {
+			inner = ""
+		}
+		return src[:object.Open+1] + "\n" + indent + entry + inner + "\n" + lineIndent(src, object.Open) + src[object.Close:], nil
+	}
+	// the new member goes on the line after the last one, so a comment at the end of that line stays with it
+	last := object.Members[len(object.Members)-1]
+	insert := jsoncLineEnd(src, last.End)
+	comma, trailing := ",", ""
+	if last.End != last.ValueEnd {
+		// the last member has a trailing comma, so the new one gets one as well
+		comma, trailing = "", ","
+	}
+	return src[:last.ValueEnd] + comma + src[last.ValueEnd:insert] + "\n" + indent + entry + trailing + src[insert:], nil
+}
+
+// jsoncLineEnd returns the end of the spaces and comments following pos on the same line, before the line break.
+// A block comment continuing on the next line is not included.
+func jsoncLineEnd(src string, pos int) int {
+	end := pos
+	for pos < len(src) {
+		switch {
+		case src[pos] == ' ' || src[pos] == '\t':
+			pos++
+		case strings.HasPrefix(src[pos:], "//"):
+			lineEnd := strings.IndexByte(src[pos:], '\n')
+			if lineEnd < 0 {
+				return len(src)
+			}
+			return pos + len(strings.TrimRight(src[pos:pos+lineEnd], "\r"))
+		case strings.HasPrefix(src[pos:], "/*"):
+			commentEnd := strings.Index(src[pos+2:], "*/")
+			if commentEnd < 0 || strings.Contains(src[pos:pos+2+commentEnd], "\n") {
+				return end
+			}
+			pos += commentEnd + 4
+			end = pos
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+// removeJSONCMember removes the member at index i with its comma.
+func removeJSONCMember(src string, object jsoncObject, i int) string {
+	member := object.Members[i]
+	switch {
+	case i+1 < len(object.Members):
+		return src[:member.Start] + src[object.Members[i+1].Start:]
+	case i > 0:
+		// the comma of the previous member goes, a comment after it stays on its line
+		previous := object.Members[i-1]
+		return src[:previous.ValueEnd] + src[previous.End:jsoncLineEnd(src, previous.End)] + src[member.End:]
+	}
+	return src[:member.Start] + src[member.End:]
+}
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONCRoot(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		keys []string
+	}{
+		{"empty object", "{}", nil},
+		{"comments", "// settings\n{\n  /* first */ \"a\": 1, // one\n  \"b\": \"//not a comment\" /* two */\n}\n", []string{"a", "b"}},
+		{"trailing commas", `{"a": [1, 2,], "b": {"c": true,},}`, []string{"a", "b"}},
+		{"nested", `{"a": {"b": {"c": [{"d": null}]}}, "e": -1.5e3}`, []string{"a", "e"}},
+		{"escaped key", `{"a\"b": "c\\", "é": 1}`, []string{`a"b`, "é"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseJSONCRoot(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for _, member := range root.Members {
+				keys = append(keys, member.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("keys = %q, want %q", keys, tt.keys)
+			}
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(stripJSONC(tt.src)), &decoded); err != nil {
+				t.Errorf("stripped source is not JSON: %v\n%s", err, stripJSONC(tt.src))
+			}
+		})
+	}
+}
+
+func TestParseJSONCRootErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"[]",
+		`{"a": 1`,
+		`{"a" 1}`,
+		`{a: 1}`,
+		`{"a": 1 "b": 2}`,
+		`{"a": [1 2]}`,
+		`{"a": "open}`,
+		`{"a": }`,
+	} {
+		if _, err := parseJSONCRoot(src); err == nil {
+			t.Errorf("parseJSONCRoot(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestSetJSONCMember(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		key   string
+		value interface{}
+		want  string
+	}{
+		{
+			"replace keeps comments",
+			"{\n  // shells\n  \"bash\": 1, // keep\n  \"zsh\": 2\n}\n",
+			"bash", 3,
+			"{\n  // shells\n  \"bash\": 3, // keep\n  \"zsh\": 2\n}\n",
+		},
+		{
+			"missing key after a comment",
+			"{\n  \"bash\": {\"a\": 1}, // keep\n}\n",
+			"dev", 2,
+			"{\n  \"bash\": {\"a\": 1}, // keep\n  \"dev\": 2,\n}\n",
+		},
+		{
+			"missing key after a comment without a comma",
+			"{\n  \"bash\": 1 // keep\n}\n",
+			"dev", 2,
+			"{\n  \"bash\": 1, // keep\n  \"dev\": 2\n}\n",
+		},
+		{
+			"missing key after a block comment",
+			"{\n  \"bash\": 1 /* keep */\n}\n",
+			"dev", 2,
+			"{\n  \"bash\": 1, /* keep */\n  \"dev\": 2\n}\n",
+		},
+		{
+			"missing key before a block comment on the next lines",
+			"{\n  \"bash\": 1 /* about\n  the end */\n}\n",
+			"dev", 2,
+			"{\n  \"bash\": 1,\n  \"dev\": 2 /* about\n  the end */\n}\n",
+		},
+		{
+			"missing key on one line",
+			`{"bash": 1}`,
+			"dev", 2,
+			"{\"bash\": 1,\n    \"dev\": 2}",
+		},
+		{
+			"empty object",
+			"{}",
+			"dev", map[string]interface{}{"a": 1},
+			"{\n    \"dev\": {\n        \"a\": 1\n    }\n}",
+		},
+		{
+			"empty object with a comment",
+			"{\n  // nothing yet\n}",
+			"dev", 2,
+			"{\n    \"dev\": 2\n  // nothing yet\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseJSONCRoot(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := setJSONCMember(tt.src, root, tt.key, tt.value, jsoncIndentUnit(tt.src, root))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+			if _, err := parseJSONCRoot(got); err != nil {
+				t.Errorf("result does not parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestRemoveJSONCMember(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		key  string
+		want string
+	}{
+		{"first", "{\n  \"a\": 1,\n  \"b\": 2\n}", "a", "{\n  \"b\": 2\n}"},
+		{"last keeps the comment before it", "{\n  \"a\": 1, // one\n  \"b\": 2\n}", "b", "{\n  \"a\": 1 // one\n}"},
+		{"last with a trailing comma", "{\n  \"a\": 1,\n  \"b\": 2,\n}", "b", "{\n  \"a\": 1\n}"},
+		{"only", "{\"a\": 1}", "a", "{}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseJSONCRoot(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			i, ok := root.find(tt.key)
+			if !ok {
+				t.Fatalf("%s not found", tt.key)
+			}
+			if got := removeJSONCMember(tt.src, root, i); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	root, err := parseJSONCRoot(`{"a": 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, ok := root.find("missing"); ok || i != -1 {
+		t.Errorf("find(missing) = %d, %v, want -1, false", i, ok)
+	}
+}
@@ -2,48 +2,110 @@ package utils
 
 import (
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
 )
 
 func SplitProfiles(profiles string) []string {
 	return strings.Split(profiles, ",")
 }
 
-func LaunchProfilesFromCmd(profiles string, shell string) error {
+// NewLaunchRequest builds the request for launching the profiles in a shell, including the generated script.
+func NewLaunchRequest(shell types.ShellItem, profiles []string, env []string, workDir string, args []string) launcher.Request {
+	req := launcher.Request{
+		Shell:     shell.GetShortName(),
+		Kind:      shell.Kind,
+		ShellPath: shell.Path,
+		Profiles:  profiles,
+		Env:       env,
+		Args:      args,
+		WorkDir:   workDir,
+		Mode:      launcher.ModeWindow,
+	}
+	req.Script = BuildLaunchScript(req)
+	return req
+}
+
+// WriteLaunchSummary prints the resolved environment, working directory and warnings of a request.
+func WriteLaunchSummary(out io.Writer, req launcher.Request, warnings []string) {
+	fmt.Fprintln(out, FormatEnvSummary(req.Env))
+	fmt.Fprintln(out, FormatWorkDirSummary(req.WorkDir))
+	fmt.Fprintln(out, "Command:", launcher.FormatCommandLine(req.CommandLine("<script>")))
+	if req.Mode.Tmux() {
+		fmt.Fprintln(out, "Tmux:", launcher.FormatCommandLine(launcher.TmuxCommand(req, "<script>")))
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(out, "Warning:", warning)
+	}
+}
+
+func PrepareProfilesFromCmd(profiles string, shell string, workDir string) (launcher.Request, []string, error) {
 	var profileList []string
-	shellPath, err := exec.LookPath(shell)
+	shellItem, err := FindShell(shell)
 	if err != nil {
-		l.Logger.Error("Failed to find shell", "Error", err)
+		l.Logger.Warn("Shell is not a known shell, looking it up on the path", "shell", shell, "Error", err)
+		shellItem.ShortName = shell
+		path, lookErr := exec.LookPath(shell)
+		if lookErr != nil {
+			l.Logger.Error("Failed to find shell", "Error", lookErr)
+			return launcher.Request{}, nil, err
+		}
+		shellItem.Path = path
+		shellItem.Kind = ShellKind(path)
 	}
 
 	for _, profile := range SplitProfiles(profiles) {
 		p, errProfile := GetProfileProperties(profile)
 		if errProfile != nil {
 			l.Logger.Error("Failed to get profile properties", "Error", errProfile)
-			return errProfile
+			return launcher.Request{}, nil, errProfile
 		}
 		if p.Shell == shell {
 			profileList = append(profileList, profile)
 		}
-		if profileList == nil {
-			l.Logger.Warn("No profiles passed were validated for the shell", "shell", shell)
-			return fmt.Errorf("no profiles passed were validated for the shell")
-		}
-		merged := MergeSelectedProfiles(profileList)
-		encodedcommand, encodeErr := EncodeCommand(merged)
-		if encodeErr != nil {
-			l.Logger.Error("Failed to encode command", "Error", encodeErr)
-			return encodeErr
-		}
-		launcherErr := launcher.ExecutePowerShellProcess(encodedcommand, shellPath)
-		if launcherErr != nil {
-			l.Logger.Error("Failed to launch profiles", "Error", launcherErr)
-			return launcherErr
+	}
+	if profileList == nil {
+		l.Logger.Warn("No profiles passed were validated for the shell", "shell", shell)
+		return launcher.Request{}, nil, fmt.Errorf("no profiles passed were validated for the shell")
+	}
+
+	env, warnings := MergeProfileEnv(profileList)
+	if workDir != "" {
+		resolved, workDirErr := ResolveWorkDir(workDir)
+		if workDirErr != nil {
+			l.Logger.Error("Invalid working directory", "Error", workDirErr)
+			return launcher.Request{}, nil, workDirErr
 		}
+		workDir = resolved
+	} else {
+		var workDirWarnings []string
+		workDir, workDirWarnings = MergeProfileWorkDir(profileList)
+		warnings = append(warnings, workDirWarnings...)
+	}
+	args, argsWarnings := ResolveArgs(shellItem, nil, profileList)
+	warnings = append(warnings, argsWarnings...)
+	return NewLaunchRequest(shellItem, profileList, env, workDir, args), warnings, nil
+}
+
+func LaunchProfilesFromCmd(launch launcher.Launcher, profiles string, shell string, workDir string, mode launcher.Mode, wait bool, out io.Writer) (launcher.Result, error) {
+	req, warnings, err := PrepareProfilesFromCmd(profiles, shell, workDir)
+	if err != nil {
+		return launcher.Result{}, err
+	}
+	req.Mode = mode
+	req.Wait = wait
+	WriteLaunchSummary(out, req, warnings)
+
+	result, launcherErr := launch.Launch(req)
+	if launcherErr != nil {
+		l.Logger.Error("Failed to launch profiles", "Error", launcherErr)
+		return result, launcherErr
 	}
-	return nil
+	fmt.Fprintf(out, "%s %s\n", shell, result)
+	return result, nil
 }
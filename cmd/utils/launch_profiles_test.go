@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+)
+
+func TestSetLaunchRequestThroughRecordingLauncher(t *testing.T) {
+	dir := t.TempDir()
+	shellPath := writeTestShell(t, dir)
+	useTestConfig(t, testShellConfig(shellPath))
+	first := writeTestProfile(t, dir, "First.Profile.sh", "SHELL:testbash:SHELL", "ENV:FOO=bar:ENV", "WORKDIR:"+dir+":WORKDIR")
+	second := writeTestProfile(t, dir, "Second.Profile.sh", "SHELL:testbash:SHELL", "ENV:BAZ=qux:ENV")
+
+	req, warnings, err := SetLaunchRequest(Set{Name: "My Dev", Shell: "testbash", Profiles: []string{first, second}, Inline: true})
+	if err != nil {
+		t.Fatalf("SetLaunchRequest: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+	recorder := &launcher.RecordingLauncher{}
+	if _, err := recorder.Launch(req); err != nil {
+		t.Fatal(err)
+	}
+	requests := recorder.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("recorded %d requests, want 1", len(requests))
+	}
+	got := requests[0]
+	if got.Name != "My Dev" || got.Shell != "testbash" || got.Kind != launcher.KindBash || got.ShellPath != shellPath {
+		t.Errorf("request = %s %s %s %s, want My Dev testbash bash %s", got.Name, got.Shell, got.Kind, got.ShellPath, shellPath)
+	}
+	if got.Mode != launcher.ModeInline {
+		t.Errorf("Mode = %s, want inline", got.Mode)
+	}
+	if got.WorkDir != dir {
+		t.Errorf("WorkDir = %q, want %q", got.WorkDir, dir)
+	}
+	if strings.Join(got.Env, " ") != "FOO=bar BAZ=qux" {
+		t.Errorf("Env = %v, want [FOO=bar BAZ=qux]", got.Env)
+	}
+	firstAt, secondAt := strings.Index(got.Script, first), strings.Index(got.Script, second)
+	if firstAt < 0 || secondAt < firstAt {
+		t.Errorf("script does not load the profiles in order:\n%s", got.Script)
+	}
+}
+
+func TestSetLaunchRequestMissingProfile(t *testing.T) {
+	dir := t.TempDir()
+	useTestConfig(t, testShellConfig(writeTestShell(t, dir)))
+	_, _, err := SetLaunchRequest(Set{Name: "Gone", Shell: "testbash", Profiles: []string{dir + "/Missing.Profile.sh"}})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("err = %v, want a missing profile error", err)
+	}
+}
+
+func TestLaunchProfilesFromCmdThroughRecordingLauncher(t *testing.T) {
+	dir := t.TempDir()
+	useTestConfig(t, testShellConfig(writeTestShell(t, dir)))
+	bash := writeTestProfile(t, dir, "Bash.Profile.sh", "SHELL:testbash:SHELL", "ENV:FOO=bar:ENV")
+	pwsh := writeTestProfile(t, dir, "Pwsh.Profile.ps1", "SHELL:pwsh:SHELL")
+
+	recorder := &launcher.RecordingLauncher{}
+	var out bytes.Buffer
+	if _, err := LaunchProfilesFromCmd(recorder, bash+","+pwsh, "testbash", "", launcher.ModeWindow, true, &out); err != nil {
+		t.Fatalf("LaunchProfilesFromCmd: %v", err)
+	}
+	requests := recorder.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("recorded %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	// profiles of other shells are left out
+	if len(req.Profiles) != 1 || req.Profiles[0] != bash {
+		t.Errorf("Profiles = %v, want [%s]", req.Profiles, bash)
+	}
+	if req.Mode != launcher.ModeWindow || !req.Wait {
+		t.Errorf("Mode = %s, Wait = %v, want window and true", req.Mode, req.Wait)
+	}
+	if !strings.Contains(out.String(), "FOO=bar") || !strings.Contains(out.String(), "Working directory:") {
+		t.Errorf("summary is missing the environment or the working directory:\n%s", out.String())
+	}
+
+	recorder.Err = errors.New("launch failed")
+	if _, err := LaunchProfilesFromCmd(recorder, bash, "testbash", "", launcher.ModeWindow, false, &out); !errors.Is(err, recorder.Err) {
+		t.Errorf("err = %v, want the launcher error", err)
+	}
+	if len(recorder.Requests()) != 2 {
+		t.Errorf("recorded %d requests, want 2", len(recorder.Requests()))
+	}
+}
+
+func TestLaunchProfilesFromCmdNoProfileForShell(t *testing.T) {
+	dir := t.TempDir()
+	useTestConfig(t, testShellConfig(writeTestShell(t, dir)))
+	pwsh := writeTestProfile(t, dir, "Pwsh.Profile.ps1", "SHELL:pwsh:SHELL")
+
+	recorder := &launcher.RecordingLauncher{}
+	if _, err := LaunchProfilesFromCmd(recorder, pwsh, "testbash", "", launcher.ModeWindow, false, &bytes.Buffer{}); err == nil {
+		t.Error("LaunchProfilesFromCmd succeeded, want an error")
+	}
+	if len(recorder.Requests()) != 0 {
+		t.Errorf("recorded %d requests, want none", len(recorder.Requests()))
+	}
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
-	"os"
+	"fmt"
+	"strings"
 
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
 )
 
@@ -15,28 +17,51 @@ func MergeSelectedProfiles(selected []string) string {
 			l.Logger.Warn("Error reading profile content", "Error", err)
 			continue
 		}
+		merged += fmt.Sprintf("# ----- Profile: %s -----\n", selected[i])
 		merged += content + "\n"
 	}
 	return merged
 }
 
-// create temp file with merged profiles
+// GenerateScriptPrologue describes the launch at the top of the generated script.
+func GenerateScriptPrologue(req launcher.Request) string {
+	var b strings.Builder
+	b.WriteString("# ----- Generated by GoPowerShellLauncher -----\n")
+	b.WriteString(req.Adapter().SelfDelete() + "\n")
+	if start, _ := req.Adapter().CaptureErrors(); start != "" {
+		b.WriteString(start + "\n")
+	}
+	fmt.Fprintf(&b, "# Shell: %s\n", req.Shell)
+	fmt.Fprintf(&b, "# Command: %s\n", launcher.FormatCommandLine(req.CommandLine("<script>")))
+	for _, profile := range req.Profiles {
+		fmt.Fprintf(&b, "# Profile: %s\n", profile)
+	}
+	if req.WorkDir != "" {
+		fmt.Fprintf(&b, "# Working directory: %s\n", req.WorkDir)
+	}
+	for _, pair := range req.Env {
+		fmt.Fprintf(&b, "# Environment: %s\n", pair)
+	}
+	return b.String()
+}
+
+// BuildLaunchScript returns the exact script that is written for a launch, the generated prologue followed by each profile.
+func BuildLaunchScript(req launcher.Request) string {
+	script := GenerateScriptPrologue(req) + MergeSelectedProfiles(req.Profiles)
+	if _, end := req.Adapter().CaptureErrors(); end != "" {
+		script += end + "\n"
+	}
+	return script
+}
+
+// create temp file with merged profiles in the launcher runtime directory
 func CreateTempFile(merged string) (string, error) {
 	l.Logger.Info("Creating temp file", "Merged", merged)
-	tempDir := os.TempDir()
-	tempFile, err := os.CreateTemp(tempDir, "merged-*.ps1")
+	tempFile, err := launcher.WriteScript(merged)
 	if err != nil {
 		l.Logger.Error("Failed to create temp file", "Error", err)
 		return "", err
 	}
-	defer tempFile.Close()
-
-	_, err = tempFile.WriteString(merged)
-	if err != nil {
-		l.Logger.Error("Failed to write to temp file", "Error", err)
-		return "", err
-	}
-
-	l.Logger.Info("Temp file created successfully", "TempFile", tempFile.Name())
-	return tempFile.Name(), nil
+	l.Logger.Info("Temp file created successfully", "TempFile", tempFile)
+	return tempFile, nil
 }
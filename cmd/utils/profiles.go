@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
 )
@@ -27,7 +28,7 @@ func LoadProfilesFromDir() ([]types.ProfileItem, error) {
 				l.Logger.Error("Failed to access path", "path", path, "error", err)
 				return err
 			}
-			if !d.IsDir() && strings.Contains(d.Name(), ".Profile.ps1") {
+			if !d.IsDir() && launcher.IsProfileFile(d.Name()) {
 				fullPath := path
 				processedFiles = append(processedFiles, fullPath)
 				l.Logger.Info("File processed", "file", fullPath)
@@ -46,7 +47,7 @@ func LoadProfilesFromDir() ([]types.ProfileItem, error) {
 		}
 		for _, file := range files {
 			l.Logger.Info("Processing file", "file", file.Name())
-			if !file.IsDir() && strings.Contains(file.Name(), ".Profile.ps1") {
+			if !file.IsDir() && launcher.IsProfileFile(file.Name()) {
 				fullPath := filepath.Join(directory, file.Name())
 				processedFiles = append(processedFiles, fullPath)
 				l.Logger.Info("File processed", "file", fullPath)
@@ -81,6 +82,36 @@ func ExtractString(input string, pattern string) (string, error) {
 	return matches[1], nil
 }
 
+func ExtractAllStrings(input string, pattern string) []string {
+	re := regexp.MustCompile(pattern)
+
+	var values []string
+	for _, matches := range re.FindAllStringSubmatch(input, -1) {
+		if len(matches) < 2 {
+			continue
+		}
+		values = append(values, matches[1])
+	}
+	return values
+}
+
+// HeaderLines returns the 1-based line of each match of pattern in content.
+func HeaderLines(content string, pattern string) []int {
+	re := regexp.MustCompile(pattern)
+	var lines []int
+	for _, loc := range re.FindAllStringIndex(content, -1) {
+		lines = append(lines, strings.Count(content[:loc[0]], "\n")+1)
+	}
+	return lines
+}
+
+func firstHeaderLine(content string, pattern string) int {
+	if lines := HeaderLines(content, pattern); len(lines) > 0 {
+		return lines[0]
+	}
+	return 0
+}
+
 func GetProfileProperties(path string) (types.ProfileItem, error) {
 	l.Logger.Info("Getting profile properties", "path", path)
 	// Get the .Profile.ps1 content, parse the file to get the required SHELL and DESCRIPTION using regex with these patterns:
@@ -91,20 +122,58 @@ func GetProfileProperties(path string) (types.ProfileItem, error) {
 		l.Logger.Error("Failed to read file", "path", path, "error", readerr)
 		return types.ProfileItem{}, readerr
 	}
+	var issues []types.ValidationIssue
 	shell, shellerr := ExtractString(string(content), `### SHELL:(.*):SHELL ###`)
 	if shellerr != nil {
 		l.Logger.Error("Failed to extract shell", "error", shellerr)
 		shell = "InvalidShell"
+		issues = append(issues, types.ValidationIssue{Line: 1, Message: "missing ### SHELL:<shell>:SHELL ### header"})
 	}
 	description, descerr := ExtractString(string(content), `### DESCRIPTION:(.*):DESCRIPTION ###`)
 	if descerr != nil {
 		l.Logger.Error("Failed to extract description", "error", descerr)
 		description = ""
 	}
+	envHeaders := ExtractAllStrings(string(content), `### ENV:(.*):ENV ###`)
+	env, enverr := ParseEnvHeaders(envHeaders)
+	if enverr != nil {
+		l.Logger.Error("Failed to parse environment variables", "path", path, "error", enverr)
+		envLines := HeaderLines(string(content), `### ENV:(.*):ENV ###`)
+		for i, header := range envHeaders {
+			if _, err := ParseEnvHeaders([]string{header}); err != nil && i < len(envLines) {
+				issues = append(issues, types.ValidationIssue{Line: envLines[i], Message: err.Error()})
+			}
+		}
+	}
+	workDir, _ := ExtractString(string(content), `### WORKDIR:(.*):WORKDIR ###`)
+	var args []string
+	if argsHeader, argserr := ExtractString(string(content), `### ARGS:(.*):ARGS ###`); argserr == nil {
+		// an empty header is kept as no arguments rather than the defaults
+		args = []string{}
+		parsed, parseerr := SplitArgs(argsHeader)
+		if parseerr != nil {
+			l.Logger.Error("Failed to parse shell arguments", "path", path, "error", parseerr)
+			issues = append(issues, types.ValidationIssue{Line: firstHeaderLine(string(content), `### ARGS:(.*):ARGS ###`), Message: parseerr.Error()})
+		}
+		args = append(args, parsed...)
+	}
+	shellVersion, _ := ExtractString(string(content), `### SHELLVERSION:(.*):SHELLVERSION ###`)
+	shellVersion = strings.TrimSpace(shellVersion)
+	if HeaderLines(string(content), `### SHELLVERSION:(.*):SHELLVERSION ###`) != nil {
+		if err := ValidateVersionConstraint(shellVersion); err != nil {
+			l.Logger.Error("Failed to parse shell version constraint", "path", path, "error", err)
+			issues = append(issues, types.ValidationIssue{Line: firstHeaderLine(string(content), `### SHELLVERSION:(.*):SHELLVERSION ###`), Message: err.Error()})
+			shellVersion = ""
+		}
+	}
 	p := types.ProfileItem{
 		Path:            path,
 		Shell:           shell,
+		ShellVersion:    shellVersion,
 		ItemDescription: description,
+		Env:             env,
+		WorkDir:         strings.TrimSpace(workDir),
+		Args:            args,
 	}
 	p.ItemTitle = p.GetName()
 	p.Name = p.GetName()
@@ -119,11 +188,16 @@ func GetProfileProperties(path string) (types.ProfileItem, error) {
 		l.Logger.Error(fmt.Sprintf("Failed to validate shell version %s", p.Shell), "error", shellerr)
 	}
 	p.IsValidShellVersion = isValidShell
+	if shellerr != nil && p.Shell != "InvalidShell" {
+		issues = append(issues, types.ValidationIssue{Line: firstHeaderLine(string(content), `### SHELL:(.*):SHELL ###`), Message: shellerr.Error()})
+	}
 	isValidDescription, descerr := ValidateDescription(p.ItemDescription)
 	if descerr != nil {
 		l.Logger.Error(fmt.Sprintf("Failed to validate description %s", p.ItemDescription), "error", descerr)
+		issues = append(issues, types.ValidationIssue{Line: firstHeaderLine(string(content), `### DESCRIPTION:(.*):DESCRIPTION ###`), Message: descerr.Error()})
 	}
 	p.IsValidDescription = isValidDescription
+	p.Issues = issues
 	p.IsValid = p.IsValidPath && p.IsValidShellVersion && p.IsValidDescription
 	l.Logger.Info("Profile loaded", "profile", p)
 	return p, nil
@@ -0,0 +1,278 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/spf13/viper"
+)
+
+func LoadSets() ([]Set, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return append([]Set{}, config.Sets...), nil
+}
+
+// FindSet returns the set with the name, ignoring case.
+func FindSet(name string) (Set, error) {
+	sets, err := LoadSets()
+	if err != nil {
+		return Set{}, err
+	}
+	for _, set := range sets {
+		if strings.EqualFold(set.Name, name) {
+			return set, nil
+		}
+	}
+	return Set{}, fmt.Errorf("no set named %q", name)
+}
+
+// ValidateSet checks a set before it is saved.
+func ValidateSet(set Set) error {
+	if strings.TrimSpace(set.Name) == "" {
+		return fmt.Errorf("set name cannot be empty")
+	}
+	if strings.ContainsAny(set.Name, `,"`) {
+		return fmt.Errorf("set name cannot contain commas or quotes")
+	}
+	if len(set.Profiles) == 0 {
+		return fmt.Errorf("set %s has no profiles", set.Name)
+	}
+	if _, err := FindShell(set.Shell); err != nil {
+		return err
+	}
+	for _, profile := range set.Profiles {
+		if _, err := os.Stat(profile); err != nil {
+			return fmt.Errorf("profile does not exist: %s", profile)
+		}
+	}
+	return nil
+}
+
+// SaveSet adds a set, or replaces the set named previousName when it is not empty.
+func SaveSet(set Set, previousName string) error {
+	set.Name = strings.TrimSpace(set.Name)
+	if err := ValidateSet(set); err != nil {
+		return err
+	}
+	sets, err := LoadSets()
+	if err != nil {
+		return err
+	}
+	index := -1
+	for i, existing := range sets {
+		if previousName != "" && strings.EqualFold(existing.Name, previousName) {
+			index = i
+			continue
+		}
+		if strings.EqualFold(existing.Name, set.Name) {
+			return fmt.Errorf("a set named %q already exists", existing.Name)
+		}
+	}
+	if previousName != "" && index < 0 {
+		return fmt.Errorf("no set named %q", previousName)
+	}
+	if index >= 0 {
+		sets[index] = set
+	} else {
+		sets = append(sets, set)
+	}
+	if err := writeSets(sets); err != nil {
+		return err
+	}
+	if index >= 0 && !strings.EqualFold(previousName, set.Name) {
+		return renameShortcutSet(previousName, set.Name)
+	}
+	return nil
+}
+
+// DeleteSet removes a set. A set launched by shortcuts is kept until the shortcuts are removed.
+func DeleteSet(name string) error {
+	sets, err := LoadSets()
+	if err != nil {
+		return err
+	}
+	var used []string
+	for _, shortcut := range config.Shortcuts {
+		if strings.EqualFold(shortcut.Set, name) {
+			used = append(used, shortcut.Name)
+		}
+	}
+	if len(used) > 0 {
+		return fmt.Errorf("set %s is launched by the shortcuts %s, remove them first", name, strings.Join(used, ", "))
+	}
+	for i, set := range sets {
+		if strings.EqualFold(set.Name, name) {
+			return writeSets(append(sets[:i], sets[i+1:]...))
+		}
+	}
+	return fmt.Errorf("no set named %q", name)
+}
+
+func writeSets(sets []Set) error {
+	l.Logger.Info("Saving sets", "count", len(sets))
+	values := make([]map[string]interface{}, len(sets))
+	for i, set := range sets {
+		values[i] = map[string]interface{}{
+			"name":     set.Name,
+			"shell":    set.Shell,
+			"profiles": set.Profiles,
+			"workdir":  set.WorkDir,
+			"inline":   set.Inline,
+		}
+		if set.Args != nil {
+			values[i]["args"] = set.Args
+		}
+		if set.Icon != "" {
+			values[i]["icon"] = set.Icon
+		}
+		if set.ColorScheme != "" {
+			values[i]["color_scheme"] = set.ColorScheme
+		}
+	}
+	viper.Set("sets", values)
+	if err := SaveConfig(); err != nil {
+		return err
+	}
+	config.Sets = sets
+	return nil
+}
+
+// ErrShortcutsOutdated is returned after a set was renamed when shortcut files could not be written again,
+// they still launch the old name.
+var ErrShortcutsOutdated = errors.New("shortcuts still launch the old set name")
+
+// renameShortcutSet keeps the shortcuts of a renamed set pointing at it, the records and the shortcut files.
+func renameShortcutSet(previousName string, name string) error {
+	shortcuts := append([]Shortcut{}, config.Shortcuts...)
+	var renamed []Shortcut
+	for i := range shortcuts {
+		if strings.EqualFold(shortcuts[i].Set, previousName) {
+			shortcuts[i].Set = name
+			renamed = append(renamed, shortcuts[i])
+		}
+	}
+	if len(renamed) == 0 {
+		return nil
+	}
+	if err := writeShortcuts(shortcuts); err != nil {
+		return err
+	}
+	var failed []string
+	for _, shortcut := range renamed {
+		// a shortcut whose file was deleted is only kept in the records
+		if _, err := os.Stat(ShortcutFile(shortcut)); err != nil {
+			continue
+		}
+		l.Logger.Info("Writing shortcut of renamed set again", "shortcut", shortcut.Name, "set", name)
+		if err := RegenerateShortcut(shortcut); err != nil {
+			l.Logger.Error("Failed to write shortcut of renamed set", "shortcut", shortcut.Name, "error", err)
+			failed = append(failed, fmt.Sprintf("%s: %v", shortcut.Name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%w, regenerate them: %s", ErrShortcutsOutdated, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func writeShortcuts(shortcuts []Shortcut) error {
+	values := make([]map[string]interface{}, len(shortcuts))
+	for i, shortcut := range shortcuts {
+		values[i] = map[string]interface{}{
+			"id":          shortcut.ID,
+			"name":        shortcut.Name,
+			"destination": shortcut.Destination,
+			"set":         shortcut.Set,
+		}
+		if shortcut.HotKey != "" {
+			values[i]["hotkey"] = shortcut.HotKey
+		}
+		if len(shortcut.Profiles) > 0 {
+			profiles := make([]map[string]interface{}, len(shortcut.Profiles))
+			for j, profile := range shortcut.Profiles {
+				profiles[j] = map[string]interface{}{"name": profile.Name, "path": profile.Path}
+			}
+			values[i]["profiles"] = profiles
+		}
+	}
+	viper.Set("shortcuts", values)
+	if err := SaveConfig(); err != nil {
+		return err
+	}
+	config.Shortcuts = shortcuts
+	return nil
+}
+
+// AddShortcut records a shortcut in the configuration, replacing one with the same name and destination.
+func AddShortcut(shortcut Shortcut) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	shortcuts := append([]Shortcut{}, config.Shortcuts...)
+	for i, existing := range shortcuts {
+		if strings.EqualFold(existing.Name, shortcut.Name) && samePath(existing.Destination, shortcut.Destination) {
+			shortcut.ID = existing.ID
+			shortcuts[i] = shortcut
+			return writeShortcuts(shortcuts)
+		}
+	}
+	if shortcut.ID == "" {
+		shortcut.ID = GenerateUniqueID()
+	}
+	return writeShortcuts(append(shortcuts, shortcut))
+}
+
+// SaveConfig writes the configuration back to the file it was read from.
+func SaveConfig() error {
+	if _, err := LoadConfig(); err != nil {
+		return err
+	}
+	if err := viper.WriteConfig(); err != nil {
+		l.Logger.Error("Failed to write configuration", "path", viper.ConfigFileUsed(), "error", err)
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	l.Logger.Info("Configuration saved", "path", viper.ConfigFileUsed())
+	return nil
+}
+
+// SetLaunchRequest builds the launch request for a set. The set working directory overrides the profile headers.
+func SetLaunchRequest(set Set) (launcher.Request, []string, error) {
+	shell, err := FindShell(set.Shell)
+	if err != nil {
+		return launcher.Request{}, nil, err
+	}
+	for _, profile := range set.Profiles {
+		if _, err := os.Stat(profile); err != nil {
+			return launcher.Request{}, nil, fmt.Errorf("profile in set %s does not exist: %s", set.Name, profile)
+		}
+	}
+	env, warnings := MergeProfileEnv(set.Profiles)
+	workDir := set.WorkDir
+	if workDir != "" {
+		resolved, err := ResolveWorkDir(workDir)
+		if err != nil {
+			return launcher.Request{}, nil, err
+		}
+		workDir = resolved
+	} else {
+		var workDirWarnings []string
+		workDir, workDirWarnings = MergeProfileWorkDir(set.Profiles)
+		warnings = append(warnings, workDirWarnings...)
+	}
+	args, argsWarnings := ResolveArgs(shell, set.Args, set.Profiles)
+	warnings = append(warnings, argsWarnings...)
+	req := NewLaunchRequest(shell, set.Profiles, env, workDir, args)
+	req.Name = set.Name
+	if set.Inline {
+		req.Mode = launcher.ModeInline
+	}
+	return req, warnings, nil
+}
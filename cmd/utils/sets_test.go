@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/shelllink"
+)
+
+func TestRenameSetRewritesShortcuts(t *testing.T) {
+	dir := t.TempDir()
+	profile := writeTestProfile(t, dir, "Dev.Profile.sh", "SHELL:testbash:SHELL")
+	useTestConfig(t, testShellConfig(writeTestShell(t, dir))+fmt.Sprintf("sets:\n  - name: Old\n    shell: testbash\n    profiles: [%s]\n", profile))
+	set, err := FindSet("Old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateShortcut(set, Shortcut{Name: "Dev", Destination: dir}); err != nil {
+		t.Fatalf("CreateShortcut: %v", err)
+	}
+
+	set.Name = "New"
+	if err := SaveSet(set, "Old"); err != nil {
+		t.Fatalf("SaveSet: %v", err)
+	}
+	shortcut, err := FindShortcut("Dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shortcut.Set != "New" {
+		t.Errorf("shortcut set = %q, want New", shortcut.Set)
+	}
+	link, err := shelllink.ReadFile(ShortcutFile(shortcut))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Arguments != `launch "New"` {
+		t.Errorf("shortcut arguments = %q, want the new set name", link.Arguments)
+	}
+}
+
+func TestDeleteSetUsedByShortcut(t *testing.T) {
+	dir := t.TempDir()
+	profile := writeTestProfile(t, dir, "Dev.Profile.sh", "SHELL:testbash:SHELL")
+	useTestConfig(t, testShellConfig(writeTestShell(t, dir))+fmt.Sprintf("sets:\n  - name: Dev\n    shell: testbash\n    profiles: [%s]\n", profile))
+	set, err := FindSet("Dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateShortcut(set, Shortcut{Name: "Dev", Destination: dir}); err != nil {
+		t.Fatalf("CreateShortcut: %v", err)
+	}
+
+	if err := DeleteSet("dev"); err == nil || !strings.Contains(err.Error(), "shortcuts Dev") {
+		t.Errorf("DeleteSet = %v, want the set kept for the shortcut", err)
+	}
+	if _, err := FindSet("Dev"); err != nil {
+		t.Errorf("set was deleted: %v", err)
+	}
+
+	shortcut, err := FindShortcut("Dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveShortcut(shortcut, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteSet("Dev"); err != nil {
+		t.Errorf("DeleteSet after removing the shortcut: %v", err)
+	}
+}
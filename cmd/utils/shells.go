@@ -2,29 +2,190 @@ package utils
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
 )
 
+// LoadShells returns the discovered shells merged with the shells in the configuration.
+// Shells that are not installed are included with Available unset, installed shells are probed for their version.
 func LoadShells() ([]types.ShellItem, error) {
-	var shells []types.ShellItem
-	items := []types.ShellItem{
-		{ItemTitle: "PowerShell", ItemDescription: "PowerShell", Name: "PowerShell", ShortName: "powershell", ShortNames: []string{"powershell", "all"}, Path: "C:\\Windows\\System32\\WindowsPowerShell\\v1.0\\powershell.exe"},
-		{ItemTitle: "PowerShell Core", ItemDescription: "PowerShell Core", Name: "PowerShell Core", ShortName: "pwsh", ShortNames: []string{"pwsh", "all"}, Path: "C:\\Program Files\\PowerShell\\7\\pwsh.exe"},
+	shells := DiscoverShells()
+	editions := map[string]bool{}
+	if config, err := LoadConfig(); err == nil {
+		shells = applyShellConfig(shells, config.Shells)
+		for _, shellConfig := range config.Shells {
+			editions[NormalizeString(shellConfig.Name)] = shellConfig.Edition != ""
+		}
 	}
-	for s := range items {
-		l.Logger.Info("Processing shell", "shell", items[s])
-		_, err := ValidatePath(items[s].Path)
-		if err != nil {
-			l.Logger.Warn("Invalid shell path", "Error", err)
+	for i := range shells {
+		shells[i].Available = shellAvailable(shells[i].Path)
+		if !shells[i].Available {
+			l.Logger.Warn("Shell is not available", "shell", shells[i].ShortName, "path", shells[i].Path)
+			continue
 		}
-		l.Logger.Info("Shell path is valid", "shell", items[s])
-		shells = append(shells, items[s])
+		applyShellVersion(&shells[i], editions[NormalizeString(shells[i].ShortName)])
 	}
 	if len(shells) == 0 {
-		l.Logger.Error("No valid shells found")
-		return nil, fmt.Errorf("no valid shells found")
+		l.Logger.Error("No shells found")
+		return nil, fmt.Errorf("no shells found")
 	}
 	return shells, nil
 }
+
+func shellAvailable(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// ProfilesForShell returns the paths of the profiles that use the shell, in the order given.
+func ProfilesForShell(profiles []types.ProfileItem, shell types.ShellItem) []string {
+	var profilesForShell []string
+	for _, profile := range profiles {
+		l.Logger.Info("Processing profile", "profile", profile.ItemTitle)
+		for _, shortName := range shell.ShortNames {
+			l.Logger.Info("Processing short name", "shortName", shortName)
+			profileShell := NormalizeString(profile.Shell)
+			shortNameTrimmed := NormalizeString(shortName)
+			l.Logger.Info("Comparing", "profile.Shell", profileShell, "shortName", shortNameTrimmed)
+			if profileShell == shortNameTrimmed {
+				l.Logger.Info("Profile uses shell", "profile", profile.ItemTitle, "shell", shortNameTrimmed)
+				profilesForShell = append(profilesForShell, profile.Path)
+				break
+			}
+		}
+	}
+	return profilesForShell
+}
+
+// FindShellForProfile returns the first available shell that the profile can run in.
+func FindShellForProfile(profile types.ProfileItem) (types.ShellItem, error) {
+	shells, err := LoadShells()
+	if err != nil {
+		return types.ShellItem{}, err
+	}
+	var unavailable []string
+	for _, shell := range shells {
+		if len(ProfilesForShell([]types.ProfileItem{profile}, shell)) == 0 {
+			continue
+		}
+		if !shell.Available {
+			unavailable = append(unavailable, shell.ShortName)
+			continue
+		}
+		return shell, nil
+	}
+	if len(unavailable) > 0 {
+		return types.ShellItem{}, fmt.Errorf("no available shell for profile %s, not installed: %s", profile.GetName(), strings.Join(unavailable, ", "))
+	}
+	return types.ShellItem{}, fmt.Errorf("no shell found for profile %s", profile.GetName())
+}
+
+// FindShell returns the shell with the short name, such as pwsh.
+func FindShell(shortName string) (types.ShellItem, error) {
+	shells, err := LoadShells()
+	if err != nil {
+		return types.ShellItem{}, err
+	}
+	for _, shell := range shells {
+		if NormalizeString(shell.ShortName) == NormalizeString(shortName) {
+			if !shell.Available {
+				return shell, fmt.Errorf("shell %s is not available at %s", shell.ShortName, shell.Path)
+			}
+			return shell, nil
+		}
+	}
+	return types.ShellItem{}, fmt.Errorf("no shell found with the name %s", shortName)
+}
+
+// applyShellConfig overrides the discovered shells with the configured settings and adds the shells only in the configuration.
+func applyShellConfig(shells []types.ShellItem, configs []ShellConfig) []types.ShellItem {
+	for _, shellConfig := range configs {
+		name := NormalizeString(shellConfig.Name)
+		if name == "" {
+			l.Logger.Warn("Skipping configured shell without a name", "shell", shellConfig)
+			continue
+		}
+		index := -1
+		for i := range shells {
+			if NormalizeString(shells[i].ShortName) == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			shells = append(shells, types.ShellItem{ItemTitle: shellConfig.Name, ItemDescription: shellConfig.Name, Name: shellConfig.Name, ShortName: name, ShortNames: []string{name}})
+			index = len(shells) - 1
+			if shellConfig.Kind == "" {
+				shells[index].Kind = ShellKind(shellConfig.Path)
+			}
+		}
+		shell := &shells[index]
+		if shellConfig.Title != "" {
+			shell.ItemTitle = shellConfig.Title
+			shell.ItemDescription = shellConfig.Title
+			shell.Name = shellConfig.Title
+		}
+		if len(shellConfig.ShortNames) > 0 {
+			shell.ShortNames = shellConfig.ShortNames
+		}
+		if shellConfig.Path != "" {
+			shell.Path = resolveShellPath(shellConfig.Path)
+		}
+		if shellConfig.Args != nil {
+			shell.Args = shellConfig.Args
+		}
+		if shellConfig.Edition != "" {
+			shell.Edition = shellConfig.Edition
+		}
+		if shellConfig.Kind != "" {
+			shell.Kind = NormalizeString(shellConfig.Kind)
+		}
+	}
+	return shells
+}
+
+// resolveShellPath expands a configured path, a bare command name is looked up on the PATH.
+func resolveShellPath(path string) string {
+	if expanded, err := ExpandPath(path); err == nil {
+		path = expanded
+	}
+	if !strings.ContainsAny(path, `/\`) {
+		if found, err := exec.LookPath(path); err == nil {
+			return found
+		}
+	}
+	return filepath.Clean(path)
+}
+
+// ShellKind guesses the adapter kind of a shell from its executable name.
+func ShellKind(path string) string {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(path)), ".exe")
+	switch name {
+	case launcher.KindBash, launcher.KindZsh:
+		return name
+	}
+	return launcher.KindPowerShell
+}
+
+// KnownShellNames returns the short names profiles can use in the SHELL header.
+func KnownShellNames() []string {
+	names := []string{"pwsh", "powershell", "pwsh-preview", "all", "bash", "zsh"}
+	if config, err := LoadConfig(); err == nil {
+		for _, shellConfig := range config.Shells {
+			names = append(names, NormalizeString(shellConfig.Name))
+			for _, shortName := range shellConfig.ShortNames {
+				names = append(names, NormalizeString(shortName))
+			}
+		}
+	}
+	return names
+}
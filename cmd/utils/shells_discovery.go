@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
+)
+
+// shellCandidate is a shell looked for on the PATH by its command names, then in its install locations.
+type shellCandidate struct {
+	shell     types.ShellItem
+	commands  []string
+	locations []string
+	// always lists the shell even when it is not installed, so it shows as unavailable.
+	always bool
+}
+
+var (
+	discoverOnce     sync.Once
+	discoveredShells []types.ShellItem
+)
+
+// DiscoverShells returns the PowerShell installations found on this machine. The search runs once per process.
+func DiscoverShells() []types.ShellItem {
+	discoverOnce.Do(func() {
+		discoveredShells = discoverShells(shellCandidates())
+	})
+	return append([]types.ShellItem{}, discoveredShells...)
+}
+
+func discoverShells(candidates []shellCandidate) []types.ShellItem {
+	var shells []types.ShellItem
+	seen := map[string]string{}
+	for _, candidate := range candidates {
+		shell := candidate.shell
+		shell.Path = findShellExecutable(candidate)
+		if shell.Path == "" {
+			l.Logger.Info("Shell not found", "shell", shell.ShortName)
+			if !candidate.always {
+				continue
+			}
+			if len(candidate.locations) > 0 {
+				shell.Path = candidate.locations[0]
+			}
+			shells = append(shells, shell)
+			continue
+		}
+		resolved := shell.Path
+		if target, err := filepath.EvalSymlinks(shell.Path); err == nil {
+			resolved = target
+		}
+		if other, ok := seen[resolved]; ok {
+			l.Logger.Info("Skipping shell, same executable as another shell", "shell", shell.ShortName, "other", other, "path", resolved)
+			continue
+		}
+		seen[resolved] = shell.ShortName
+		l.Logger.Info("Discovered shell", "shell", shell.ShortName, "path", shell.Path)
+		shells = append(shells, shell)
+	}
+	return shells
+}
+
+func findShellExecutable(candidate shellCandidate) string {
+	for _, command := range candidate.commands {
+		if path, err := exec.LookPath(command); err == nil {
+			if abs, err := filepath.Abs(path); err == nil {
+				return abs
+			}
+			return path
+		}
+	}
+	for _, location := range candidate.locations {
+		matches, err := filepath.Glob(location)
+		if err != nil {
+			l.Logger.Warn("Invalid shell location", "location", location, "error", err)
+			continue
+		}
+		for _, match := range matches {
+			if shellAvailable(match) {
+				return match
+			}
+		}
+	}
+	return ""
+}
+
+func envOr(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
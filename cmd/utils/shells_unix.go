@@ -0,0 +1,46 @@
+//go:build !windows
+
+package utils
+
+import (
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
+)
+
+// Windows PowerShell does not exist outside Windows. Bash and zsh are listed when installed.
+func shellCandidates() []shellCandidate {
+	return []shellCandidate{
+		{
+			shell:    types.ShellItem{ItemTitle: "PowerShell Core", ItemDescription: "PowerShell Core", Name: "PowerShell Core", ShortName: "pwsh", ShortNames: []string{"pwsh", "all"}, Edition: "Core", Kind: launcher.KindPowerShell},
+			commands: []string{"pwsh"},
+			locations: []string{
+				"/usr/bin/pwsh",
+				"/usr/local/bin/pwsh",
+				"/opt/homebrew/bin/pwsh",
+				"/opt/microsoft/powershell/7/pwsh",
+				"/usr/local/microsoft/powershell/7/pwsh",
+				"/snap/bin/pwsh",
+			},
+			always: true,
+		},
+		{
+			shell:    types.ShellItem{ItemTitle: "PowerShell Preview", ItemDescription: "PowerShell Preview", Name: "PowerShell Preview", ShortName: "pwsh-preview", ShortNames: []string{"pwsh-preview", "pwsh", "all"}, Edition: "Core", Kind: launcher.KindPowerShell},
+			commands: []string{"pwsh-preview"},
+			locations: []string{
+				"/opt/microsoft/powershell/7-preview/pwsh",
+				"/usr/local/microsoft/powershell/7-preview/pwsh",
+				"/snap/bin/pwsh-preview",
+			},
+		},
+		{
+			shell:     types.ShellItem{ItemTitle: "Bash", ItemDescription: "Bash", Name: "Bash", ShortName: "bash", ShortNames: []string{"bash"}, Kind: launcher.KindBash},
+			commands:  []string{"bash"},
+			locations: []string{"/bin/bash", "/usr/bin/bash", "/usr/local/bin/bash", "/opt/homebrew/bin/bash"},
+		},
+		{
+			shell:     types.ShellItem{ItemTitle: "Zsh", ItemDescription: "Zsh", Name: "Zsh", ShortName: "zsh", ShortNames: []string{"zsh"}, Kind: launcher.KindZsh},
+			commands:  []string{"zsh"},
+			locations: []string{"/bin/zsh", "/usr/bin/zsh", "/usr/local/bin/zsh", "/opt/homebrew/bin/zsh"},
+		},
+	}
+}
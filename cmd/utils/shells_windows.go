@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
+)
+
+func shellCandidates() []shellCandidate {
+	systemRoot := envOr("SystemRoot", `C:\Windows`)
+	programFiles := envOr("ProgramFiles", `C:\Program Files`)
+	localAppData, err := os.UserCacheDir()
+	if err != nil {
+		localAppData = filepath.Join(os.Getenv("USERPROFILE"), "AppData", "Local")
+	}
+	return []shellCandidate{
+		{
+			shell:    types.ShellItem{ItemTitle: "PowerShell", ItemDescription: "PowerShell", Name: "PowerShell", ShortName: "powershell", ShortNames: []string{"powershell", "all"}, Edition: "Desktop", Kind: launcher.KindPowerShell},
+			commands: []string{"powershell.exe"},
+			locations: []string{
+				filepath.Join(systemRoot, "System32", "WindowsPowerShell", "v1.0", "powershell.exe"),
+			},
+			always: true,
+		},
+		{
+			shell:    types.ShellItem{ItemTitle: "PowerShell Core", ItemDescription: "PowerShell Core", Name: "PowerShell Core", ShortName: "pwsh", ShortNames: []string{"pwsh", "all"}, Edition: "Core", Kind: launcher.KindPowerShell},
+			commands: []string{"pwsh.exe"},
+			locations: []string{
+				filepath.Join(programFiles, "PowerShell", "7", "pwsh.exe"),
+				filepath.Join(programFiles, "PowerShell", "6", "pwsh.exe"),
+				filepath.Join(localAppData, "Microsoft", "WindowsApps", "pwsh.exe"),
+			},
+			always: true,
+		},
+		{
+			shell:    types.ShellItem{ItemTitle: "PowerShell Preview", ItemDescription: "PowerShell Preview", Name: "PowerShell Preview", ShortName: "pwsh-preview", ShortNames: []string{"pwsh-preview", "pwsh", "all"}, Edition: "Core", Kind: launcher.KindPowerShell},
+			commands: []string{"pwsh-preview.exe"},
+			locations: []string{
+				filepath.Join(programFiles, "PowerShell", "7-preview", "pwsh.exe"),
+				filepath.Join(programFiles, "PowerShell", "*-preview", "pwsh.exe"),
+				filepath.Join(localAppData, "Microsoft", "WindowsApps", "pwsh-preview.exe"),
+			},
+		},
+	}
+}
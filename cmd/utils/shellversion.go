@@ -0,0 +1,331 @@
+package utils
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/types"
+)
+
+// probeTimeout bounds how long a shell may take to report its version.
+const probeTimeout = 15 * time.Second
+
+// shellVersionInfo is a probed shell, valid while the binary keeps its modification time and size.
+// Error is set when the probe failed, so a broken shell is not run again on every start.
+type shellVersionInfo struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Version string    `json:"version"`
+	Edition string    `json:"edition"`
+	Error   string    `json:"error,omitempty"`
+}
+
+var (
+	shellVersionMu    sync.Mutex
+	shellVersionCache map[string]shellVersionInfo
+)
+
+// ShellVersionCachePath is the file the probed shell versions are kept in between runs.
+func ShellVersionCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "GoPowerShellLauncher", "shell-versions.json")
+}
+
+// ProbeShellVersion returns the version and edition of the shell at path, run with the adapter of its kind.
+// The shell is only run when the binary changed since it was last probed, a failed probe is remembered as well.
+func ProbeShellVersion(path string, kind string) (string, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", err
+	}
+	shellVersionMu.Lock()
+	defer shellVersionMu.Unlock()
+	if shellVersionCache == nil {
+		shellVersionCache = readShellVersionCache()
+	}
+	if cached, ok := shellVersionCache[path]; ok && cached.ModTime.Equal(info.ModTime()) && cached.Size == info.Size() {
+		if cached.Error != "" {
+			return "", "", errors.New(cached.Error)
+		}
+		return cached.Version, cached.Edition, nil
+	}
+
+	probed := shellVersionInfo{ModTime: info.ModTime(), Size: info.Size()}
+	probed.Version, probed.Edition, err = runShellVersionProbe(path, kind)
+	if err != nil {
+		probed.Error = err.Error()
+	}
+	shellVersionCache[path] = probed
+	if err := writeShellVersionCache(shellVersionCache); err != nil {
+		l.Logger.Warn("Failed to save shell versions", "error", err)
+	}
+	return probed.Version, probed.Edition, err
+}
+
+// runShellVersionProbe runs the version command of the adapter in the shell.
+func runShellVersionProbe(path string, kind string) (string, string, error) {
+	l.Logger.Info("Probing shell version", "path", path)
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	args, err := launcher.AdapterFor(kind).CommandArgs(launcher.AdapterFor(kind).VersionCommand())
+	if err != nil {
+		return "", "", err
+	}
+	output, err := exec.CommandContext(ctx, path, args...).Output()
+	if err != nil {
+		return "", "", fmt.Errorf("error probing %s: %w", path, err)
+	}
+	lines := strings.Fields(string(output))
+	if len(lines) == 0 {
+		return "", "", fmt.Errorf("no version reported by %s", path)
+	}
+	if _, _, err := parseShellVersion(lines[0]); err != nil {
+		return "", "", fmt.Errorf("unexpected version reported by %s: %w", path, err)
+	}
+	edition := ""
+	if len(lines) > 1 {
+		edition = lines[1]
+	}
+	return lines[0], edition, nil
+}
+
+func readShellVersionCache() map[string]shellVersionInfo {
+	cache := map[string]shellVersionInfo{}
+	content, err := os.ReadFile(ShellVersionCachePath())
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(content, &cache); err != nil {
+		l.Logger.Warn("Ignoring unreadable shell version cache", "path", ShellVersionCachePath(), "error", err)
+		return map[string]shellVersionInfo{}
+	}
+	return cache
+}
+
+func writeShellVersionCache(cache map[string]shellVersionInfo) error {
+	path := ShellVersionCachePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// applyShellVersion fills in the probed version of an available shell. A configured edition is kept.
+func applyShellVersion(shell *types.ShellItem, configuredEdition bool) {
+	version, edition, err := ProbeShellVersion(shell.Path, shell.Kind)
+	if err != nil {
+		l.Logger.Warn("Failed to probe shell version", "shell", shell.ShortName, "error", err)
+		return
+	}
+	shell.Version = version
+	if edition != "" && !configuredEdition {
+		shell.Edition = edition
+	}
+}
+
+// parseShellVersion splits a version such as 7.5.0-preview.3 into its numbers and pre-release label.
+func parseShellVersion(version string) ([]int, string, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	var pre string
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version, pre = version[:i], version[i+1:]
+	}
+	if version == "" {
+		return nil, "", fmt.Errorf("empty version")
+	}
+	parts := strings.Split(version, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, "", fmt.Errorf("invalid version %q", version)
+		}
+		numbers[i] = n
+	}
+	return numbers, pre, nil
+}
+
+// compareShellVersions compares the first n numbers of two versions, all of them when n is 0.
+// A pre-release sorts before the release with the same numbers.
+func compareShellVersions(a []int, aPre string, b []int, bPre string, n int) int {
+	length := max(len(a), len(b))
+	if n > 0 {
+		length = n
+	}
+	for i := 0; i < length; i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	if n > 0 || aPre == bPre {
+		return 0
+	}
+	switch {
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return comparePreReleases(aPre, bPre)
+}
+
+// comparePreReleases compares pre-release labels part by part, numeric parts as numbers, so preview.10 sorts after
+// preview.3. A label that is a prefix of the other sorts first.
+func comparePreReleases(a string, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < min(len(aParts), len(bParts)); i++ {
+		x, xErr := strconv.Atoi(aParts[i])
+		y, yErr := strconv.Atoi(bParts[i])
+		switch {
+		case xErr == nil && yErr == nil:
+			if x != y {
+				return cmp.Compare(x, y)
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aParts[i], bParts[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmp.Compare(len(aParts), len(bParts))
+}
+
+var versionOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+// versionConditions splits a constraint on spaces and commas, keeping an operator written apart from its version,
+// as in ">= 7.2", with that version.
+func versionConditions(constraint string) ([]string, error) {
+	var conditions []string
+	pending := ""
+	for _, field := range strings.FieldsFunc(constraint, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		if slices.Contains(versionOperators, field) {
+			if pending != "" {
+				return nil, fmt.Errorf("invalid version constraint %q: operator %s has no version", constraint, pending)
+			}
+			pending = field
+			continue
+		}
+		conditions = append(conditions, pending+field)
+		pending = ""
+	}
+	if pending != "" {
+		return nil, fmt.Errorf("invalid version constraint %q: operator %s has no version", constraint, pending)
+	}
+	return conditions, nil
+}
+
+// CheckVersionConstraint reports whether version satisfies every condition of the constraint, such as ">=7.2 <7.5".
+// Conditions are separated by spaces or commas, and an operator may be followed by a space. A version without an operator matches that version and its patches.
+func CheckVersionConstraint(constraint string, version string) (bool, error) {
+	actual, actualPre, err := parseShellVersion(version)
+	if err != nil {
+		return false, err
+	}
+	conditions, err := versionConditions(constraint)
+	if err != nil {
+		return false, err
+	}
+	for _, condition := range conditions {
+		operator := ""
+		for _, candidate := range versionOperators {
+			if strings.HasPrefix(condition, candidate) {
+				operator = candidate
+				break
+			}
+		}
+		wanted, wantedPre, err := parseShellVersion(strings.TrimPrefix(condition, operator))
+		if err != nil {
+			return false, fmt.Errorf("invalid version constraint %q: %w", condition, err)
+		}
+		var ok bool
+		switch operator {
+		case "", "=", "==":
+			ok = compareShellVersions(actual, actualPre, wanted, wantedPre, len(wanted)) == 0
+		case "!=":
+			ok = compareShellVersions(actual, actualPre, wanted, wantedPre, len(wanted)) != 0
+		case ">=":
+			ok = compareShellVersions(actual, actualPre, wanted, wantedPre, 0) >= 0
+		case "<=":
+			ok = compareShellVersions(actual, actualPre, wanted, wantedPre, 0) <= 0
+		case ">":
+			ok = compareShellVersions(actual, actualPre, wanted, wantedPre, 0) > 0
+		case "<":
+			ok = compareShellVersions(actual, actualPre, wanted, wantedPre, 0) < 0
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ValidateVersionConstraint checks the syntax of a SHELLVERSION header.
+func ValidateVersionConstraint(constraint string) error {
+	if strings.TrimSpace(constraint) == "" {
+		return fmt.Errorf("empty SHELLVERSION header")
+	}
+	_, err := CheckVersionConstraint(constraint, "0")
+	return err
+}
+
+// ShellVersionIssues returns why the shell cannot run the profiles, checked against their SHELLVERSION headers,
+// and warnings for the constraints that could not be checked.
+func ShellVersionIssues(profiles []types.ProfileItem, shell types.ShellItem) ([]string, []string) {
+	var issues, warnings []string
+	for _, profile := range profiles {
+		if profile.ShellVersion == "" {
+			continue
+		}
+		if shell.Version == "" {
+			warnings = append(warnings, fmt.Sprintf("%s version unknown, SHELLVERSION %s of %s not checked", shell.Name, profile.ShellVersion, profile.GetName()))
+			continue
+		}
+		ok, err := CheckVersionConstraint(profile.ShellVersion, shell.Version)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", profile.GetName(), err))
+			continue
+		}
+		if !ok {
+			issues = append(issues, fmt.Sprintf("%s needs %s %s", profile.GetName(), shell.ShortName, profile.ShellVersion))
+		}
+	}
+	return issues, warnings
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{">=7.2", "7.2.0", true},
+		{">=7.2", "7.1.9", false},
+		{"<=7.4", "7.4.0", true},
+		{"<=7.4", "7.4.1", false},
+		{">7.2", "7.2.0", false},
+		{">7.2", "7.2.1", true},
+		{"<7.5", "7.4.6", true},
+		{"<7.5", "7.5.0", false},
+		{"=7.4", "7.4.6", true},
+		{"==7.4", "7.5.0", false},
+		{"7.4", "7.4.6", true},
+		{"7.4", "7.40.0", false},
+		{"!=7.4", "7.4.2", false},
+		{"!=7.4", "7.5.0", true},
+		{"v7.4.6", "7.4.6", true},
+
+		// pre-releases sort before their release, numeric parts as numbers
+		{"<7.5", "7.5.0-preview.3", true},
+		{">=7.5", "7.5.0-preview.3", false},
+		{">7.5.0-preview.3", "7.5.0-preview.10", true},
+		{"<7.5.0-preview.10", "7.5.0-preview.3", true},
+		{">7.5.0-preview", "7.5.0-preview.1", true},
+		{">7.5.0-preview.1", "7.5.0-rc.1", true},
+		{"=7.5.0-rc.1", "7.5.0-rc.1", true},
+
+		// separators
+		{">=7.2 <7.5", "7.4.6", true},
+		{">=7.2,<7.5", "7.5.0", false},
+		{">=7.2, <7.5", "7.2.0", true},
+		{">= 7.2 < 7.5", "7.4.6", true},
+		{">= 7.2, < 7.5", "7.1.0", false},
+		{"  >=\t7.2  ", "7.3", true},
+	}
+	for _, tt := range tests {
+		got, err := CheckVersionConstraint(tt.constraint, tt.version)
+		if err != nil {
+			t.Errorf("CheckVersionConstraint(%q, %q): %v", tt.constraint, tt.version, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CheckVersionConstraint(%q, %q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestValidateVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		valid      bool
+	}{
+		{">=7.2 <7.5", true},
+		{">= 7.2", true},
+		{"7", true},
+		{"", false},
+		{">=", false},
+		{">= <7.5", false},
+		{">=7.x", false},
+		{">=seven", false},
+	}
+	for _, tt := range tests {
+		err := ValidateVersionConstraint(tt.constraint)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateVersionConstraint(%q) = %v, want valid %v", tt.constraint, err, tt.valid)
+		}
+	}
+}
+
+func TestProbeShellVersionCachesFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test shell is a shell script")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	shellVersionCache = nil
+	t.Cleanup(func() { shellVersionCache = nil })
+	runs := filepath.Join(dir, "runs")
+	shell := filepath.Join(dir, "broken")
+	if err := os.WriteFile(shell, []byte("#!/bin/sh\necho run >> "+runs+"\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	probeRuns := func() int {
+		content, _ := os.ReadFile(runs)
+		return strings.Count(string(content), "run")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := ProbeShellVersion(shell, "bash"); err == nil || !strings.Contains(err.Error(), "error probing") {
+			t.Fatalf("probe %d = %v, want the probe error", i, err)
+		}
+	}
+	if probeRuns() != 1 {
+		t.Errorf("the shell ran %d times, want the failure cached after 1", probeRuns())
+	}
+
+	// the cache outlives the process
+	shellVersionCache = nil
+	if _, _, err := ProbeShellVersion(shell, "bash"); err == nil || probeRuns() != 1 {
+		t.Errorf("probe after reloading the cache = %v with %d runs, want the cached failure", err, probeRuns())
+	}
+
+	// a changed binary is probed again
+	if err := os.WriteFile(shell, []byte("#!/bin/sh\necho run >> "+runs+"\necho 5.2.1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(shell, later, later); err != nil {
+		t.Fatal(err)
+	}
+	version, _, err := ProbeShellVersion(shell, "bash")
+	if err != nil || version != "5.2.1" || probeRuns() != 2 {
+		t.Errorf("probe of the changed shell = %q, %v with %d runs, want 5.2.1 after a new run", version, err, probeRuns())
+	}
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+// ShortcutFile returns the .lnk file of a shortcut.
+func ShortcutFile(shortcut Shortcut) string {
+	return filepath.Join(shortcut.Destination, shortcut.Name+".lnk")
+}
+
+// ErrSetDiffers is returned when a shortcut would replace a set of the same name with other profiles.
+var ErrSetDiffers = errors.New("a set with that name already exists with a different shell or profiles")
+
+// CheckShortcutSet returns ErrSetDiffers when a set named like the set of a new shortcut exists and differs from it.
+func CheckShortcutSet(set Set) error {
+	existing, err := FindSet(set.Name)
+	if err != nil || sameSetProfiles(existing, set) {
+		return nil
+	}
+	return fmt.Errorf("%w: set %s runs %s in %s", ErrSetDiffers, existing.Name, strings.Join(existing.Profiles, ", "), existing.Shell)
+}
+
+// SaveShortcutSet saves the set a shortcut is named after. An existing set with the same shell and profiles is kept
+// as it is, one that differs is only replaced when replace is set.
+func SaveShortcutSet(set Set, replace bool) error {
+	existing, err := FindSet(set.Name)
+	if err != nil {
+		return SaveSet(set, "")
+	}
+	if sameSetProfiles(existing, set) {
+		l.Logger.Info("Shortcut set already exists", "set", existing.Name)
+		return nil
+	}
+	if !replace {
+		return CheckShortcutSet(set)
+	}
+	l.Logger.Warn("Replacing set for shortcut", "set", existing.Name, "profiles", existing.Profiles)
+	return SaveSet(set, existing.Name)
+}
+
+func sameSetProfiles(a Set, b Set) bool {
+	if NormalizeString(a.Shell) != NormalizeString(b.Shell) || len(a.Profiles) != len(b.Profiles) {
+		return false
+	}
+	for i := range a.Profiles {
+		if !samePath(a.Profiles[i], b.Profiles[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindShortcut returns the shortcut with the ID, or with the name ignoring case.
+// A name shared by shortcuts in different folders has to be given as the ID.
+func FindShortcut(nameOrID string) (Shortcut, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return Shortcut{}, err
+	}
+	var matches []Shortcut
+	for _, shortcut := range config.Shortcuts {
+		if shortcut.ID == nameOrID {
+			return shortcut, nil
+		}
+		if strings.EqualFold(shortcut.Name, nameOrID) {
+			matches = append(matches, shortcut)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Shortcut{}, fmt.Errorf("no shortcut named %q", nameOrID)
+	case 1:
+		return matches[0], nil
+	}
+	ids := make([]string, len(matches))
+	for i, shortcut := range matches {
+		ids[i] = shortcut.ID
+	}
+	return Shortcut{}, fmt.Errorf("%d shortcuts are named %q, use one of the IDs %s", len(matches), nameOrID, strings.Join(ids, ", "))
+}
+
+// RemoveShortcut deletes the shortcut file, unless keepFile is set, and the record of the shortcut.
+// A file that is already gone is not an error. The set of the shortcut is kept.
+func RemoveShortcut(shortcut Shortcut, keepFile bool) error {
+	if !keepFile {
+		path := ShortcutFile(shortcut)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			l.Logger.Error("Failed to delete shortcut file", "path", path, "error", err)
+			return fmt.Errorf("error deleting shortcut %s: %w", path, err)
+		}
+		l.Logger.Info("Shortcut file deleted", "path", path)
+	}
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	shortcuts := append([]Shortcut{}, config.Shortcuts...)
+	for i, existing := range shortcuts {
+		if existing.ID == shortcut.ID {
+			return writeShortcuts(append(shortcuts[:i], shortcuts[i+1:]...))
+		}
+	}
+	return fmt.Errorf("no shortcut with ID %s", shortcut.ID)
+}
+
+// RegenerateShortcut writes the shortcut file again from its set, after the set or the launcher moved.
+func RegenerateShortcut(shortcut Shortcut) error {
+	if shortcut.Set == "" {
+		return fmt.Errorf("shortcut %s has no set, create it again", shortcut.Name)
+	}
+	set, err := FindSet(shortcut.Set)
+	if err != nil {
+		return fmt.Errorf("shortcut %s: %w", shortcut.Name, err)
+	}
+	return CreateShortcut(set, shortcut)
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveShortcutSet(t *testing.T) {
+	dir := t.TempDir()
+	shellPath := writeTestShell(t, dir)
+	first := writeTestProfile(t, dir, "First.Profile.sh", "SHELL:testbash:SHELL")
+	second := writeTestProfile(t, dir, "Second.Profile.sh", "SHELL:testbash:SHELL")
+	useTestConfig(t, testShellConfig(shellPath)+fmt.Sprintf("sets:\n  - name: My Dev\n    shell: testbash\n    profiles: [%s]\n    workdir: /src\n", first))
+
+	// the same profiles keep the set as it is
+	if err := SaveShortcutSet(Set{Name: "my dev", Shell: "testbash", Profiles: []string{first}}, false); err != nil {
+		t.Fatalf("SaveShortcutSet with the same profiles: %v", err)
+	}
+	if set, _ := FindSet("My Dev"); set.Name != "My Dev" || set.WorkDir != "/src" {
+		t.Errorf("set = %+v, want it unchanged", set)
+	}
+
+	other := Set{Name: "my dev", Shell: "testbash", Profiles: []string{second}}
+	if err := CheckShortcutSet(other); !errors.Is(err, ErrSetDiffers) {
+		t.Errorf("CheckShortcutSet = %v, want ErrSetDiffers", err)
+	}
+	if err := SaveShortcutSet(other, false); !errors.Is(err, ErrSetDiffers) {
+		t.Errorf("SaveShortcutSet = %v, want ErrSetDiffers", err)
+	}
+	if set, _ := FindSet("My Dev"); len(set.Profiles) != 1 || set.Profiles[0] != first {
+		t.Errorf("profiles = %v, want the set left alone", set.Profiles)
+	}
+
+	if err := SaveShortcutSet(other, true); err != nil {
+		t.Fatalf("SaveShortcutSet with replace: %v", err)
+	}
+	if set, _ := FindSet("My Dev"); len(set.Profiles) != 1 || set.Profiles[0] != second {
+		t.Errorf("profiles = %v, want the set replaced", set.Profiles)
+	}
+
+	if err := SaveShortcutSet(Set{Name: "New", Shell: "testbash", Profiles: []string{first, second}}, false); err != nil {
+		t.Fatalf("SaveShortcutSet for a new set: %v", err)
+	}
+	if _, err := FindSet("New"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPlanShortcutResolvesWorkDir(t *testing.T) {
+	dir := t.TempDir()
+	profile := writeTestProfile(t, dir, "Dev.Profile.sh", "SHELL:testbash:SHELL")
+	useTestConfig(t, testShellConfig(writeTestShell(t, dir)))
+	home := filepath.Join(dir, "home")
+	if err := os.MkdirAll(filepath.Join(home, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("SRC_DIR", "src")
+
+	for _, workDir := range []string{"~/src", "$env:HOME/%SRC_DIR%", "~/src/"} {
+		set := Set{Name: "Dev", Shell: "testbash", Profiles: []string{profile}, WorkDir: workDir}
+		plan, err := PlanShortcut(set, Shortcut{Name: "Dev", Destination: dir})
+		if err != nil {
+			t.Fatalf("PlanShortcut with %q: %v", workDir, err)
+		}
+		if want := filepath.Join(home, "src"); plan.WorkDir != want {
+			t.Errorf("working directory of %q = %q, want %q", workDir, plan.WorkDir, want)
+		}
+	}
+
+	set := Set{Name: "Dev", Shell: "testbash", Profiles: []string{profile}, WorkDir: "~/missing"}
+	if _, err := PlanShortcut(set, Shortcut{Name: "Dev", Destination: dir}); err == nil {
+		t.Error("PlanShortcut with a missing working directory succeeded, want an error")
+	}
+}
@@ -1,19 +1,15 @@
 package utils
 
 import (
-	"bytes"
 	"crypto/sha256"
-	"encoding/base64"
-	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"strings"
-	"syscall"
-	"unicode/utf16"
 
+	"github.com/ntatschner/GoPowerShellLauncher/cmd/launcher"
 	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
 	"golang.org/x/term"
 )
@@ -97,9 +93,8 @@ func ValidateHash(expectedHash, filePath string) (bool, error) {
 
 func ValidateShellVersion(shellVersion string) (bool, error) {
 	l.Logger.Info("Validating shell version", "ShellVersion", shellVersion)
-	shellVersion = strings.ToLower(shellVersion)
-	switch shellVersion {
-	case "pwsh", "powershell", "all":
+	shellVersion = NormalizeString(shellVersion)
+	if ContainsString(KnownShellNames(), shellVersion) {
 		l.Logger.Info("Shell version is valid")
 		return true, nil
 	}
@@ -150,49 +145,9 @@ func NormalizeString(s string) string {
 	return strings.ToLower(strings.TrimSpace(s))
 }
 
+// EncodeCommand encodes a PowerShell command for -EncodedCommand.
 func EncodeCommand(command string) (string, error) {
-	// Convert the string to UTF-16LE
-	utf16LE := utf16.Encode([]rune(command))
-	buf := new(bytes.Buffer)
-	for _, r := range utf16LE {
-		if err := binary.Write(buf, binary.LittleEndian, r); err != nil {
-			return "", fmt.Errorf("failed to encode command to UTF-16LE: %v", err)
-		}
-	}
-
-	// Base64 encode the UTF-16LE bytes
-	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
-	if encoded == "" {
-		return "", fmt.Errorf("failed to encode command")
-	}
-	return encoded, nil
-}
-
-func ExecuteCommandWithPowershell(encodedCmd string) error {
-	l.Logger.Debug("Executing command with PowerShell")
-	// Get path of powershell.exe
-	powershellPath, err := exec.LookPath("powershell")
-	if err != nil {
-		l.Logger.Error("Failed to find PowerShell executable", "Error", err)
-		return err
-	}
-	l.Logger.Debug("PowerShell executable found", "Path", powershellPath)
-	command := fmt.Sprintf(
-		"Start-Process -FilePath \"%s\" -ArgumentList \"-NoProfile -NonInteractive -WindowStyle Hidden -EncodedCommand %s\"",
-		powershellPath, encodedCmd,
-	)
-	l.Logger.Debug("PowerShell command", "Command", command)
-	cmd := exec.Command("cmd", "/C", "start", "/b", "/wait", "powershell", "-Command", command)
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
-	exerr := cmd.Run()
-	if exerr != nil {
-		l.Logger.Error("Failed to start PowerShell process", "Error", err)
-		return err
-	}
-	l.Logger.Debug("PowerShell process started", "PID", cmd.Process.Pid)
-	l.Logger.Info("PowerShell process started successfully")
-	return nil
+	return launcher.EncodePowerShellCommand(command)
 }
 
 func ExecuteInsideShell(encodedCmd string) error {
@@ -212,12 +167,13 @@ func ExecuteInsideShell(encodedCmd string) error {
 		return fmt.Errorf("no valid shell found")
 	}
 
-	cmd := exec.Command(fmt.Sprintf("%s -EncodedCommand %s", shell, encodedCmd))
+	cmd := exec.Command(shell, "-NoProfile", "-EncodedCommand", encodedCmd)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 	if err := cmd.Run(); err != nil {
-		fmt.Println("Error running command:", err)
+		l.Logger.Error("Error running command", "Error", err)
+		return err
 	}
 	return nil
 }
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// exportNamespace is the UUIDv5 namespace of the identifiers written by the exports, so they stay the same between runs.
+const exportNamespace = "8c1b7f3e-2d4a-4e6b-9f1c-5a7d3b2e6c48"
+
+// NewUUIDv5 returns the name-based SHA-1 UUID of name in the namespace, as defined by RFC 4122.
+func NewUUIDv5(namespace string, name string) (string, error) {
+	ns, err := hex.DecodeString(strings.ReplaceAll(strings.Trim(namespace, "{}"), "-", ""))
+	if err != nil || len(ns) != 16 {
+		return "", fmt.Errorf("invalid UUID namespace %q", namespace)
+	}
+	hash := sha1.New()
+	hash.Write(ns)
+	hash.Write([]byte(name))
+	sum := hash.Sum(nil)[:16]
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16]), nil
+}
+
+// exportID returns the stable identifier of an exported entry.
+func exportID(kind string, name string) string {
+	id, err := NewUUIDv5(exportNamespace, kind+":"+strings.ToLower(name))
+	if err != nil {
+		// the namespace is a constant, this only fails if it is edited into an invalid UUID
+		panic(err)
+	}
+	return id
+}
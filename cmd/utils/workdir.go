@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	l "github.com/ntatschner/GoPowerShellLauncher/cmd/logger"
+)
+
+// ExpandPath expands a leading ~ to the user's home directory and any environment variable references.
+func ExpandPath(path string) (string, error) {
+	path = ExpandEnvValue(strings.TrimSpace(path), os.LookupEnv)
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~\\") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error getting home directory: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return filepath.Clean(path), nil
+}
+
+// ResolveWorkDir expands and validates a working directory.
+func ResolveWorkDir(workDir string) (string, error) {
+	if strings.TrimSpace(workDir) == "" {
+		return "", nil
+	}
+	resolved, err := ExpandPath(workDir)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", fmt.Errorf("working directory is not valid: %s", resolved)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("working directory is not a directory: %s", resolved)
+	}
+	return resolved, nil
+}
+
+// MergeProfileWorkDir returns the working directory declared by the first profile that has one.
+// Profiles declaring a different directory produce a warning.
+func MergeProfileWorkDir(profilePaths []string) (string, []string) {
+	l.Logger.Info("Merging profile working directories", "profiles", profilePaths)
+	var workDir, source string
+	var warnings []string
+	for _, path := range profilePaths {
+		profile, err := GetProfileProperties(path)
+		if err != nil {
+			l.Logger.Warn("Failed to get profile properties", "path", path, "error", err)
+			continue
+		}
+		if profile.WorkDir == "" {
+			continue
+		}
+		resolved, err := ResolveWorkDir(profile.WorkDir)
+		if err != nil {
+			l.Logger.Warn("Invalid profile working directory", "path", path, "error", err)
+			warnings = append(warnings, fmt.Sprintf("%s: %v", profile.GetName(), err))
+			continue
+		}
+		if workDir == "" {
+			workDir = resolved
+			source = profile.GetName()
+			continue
+		}
+		if !samePath(workDir, resolved) {
+			l.Logger.Warn("Conflicting working directory", "workDir", workDir, "ignored", resolved, "profile", path)
+			warnings = append(warnings, fmt.Sprintf("working directory %q from %s ignored, using %q from %s", resolved, profile.GetName(), workDir, source))
+		}
+	}
+	return workDir, warnings
+}
+
+// Paths are case insensitive on Windows.
+func samePath(a, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// FormatWorkDirSummary renders the working directory for the launch summary.
+func FormatWorkDirSummary(workDir string) string {
+	if workDir == "" {
+		return "Working directory: default"
+	}
+	return "Working directory: " + workDir
+}
@@ -13,15 +13,14 @@ import (
 	"github.com/ntatschner/GoPowerShellLauncher/cmd/utils"
 )
 
-func cleanupTempFiles() {
-	for _, file := range launcher.TempFiles {
-		os.Remove(file)
-	}
-}
-
 var MousetrapHelpText = ""
 
 func main() {
+	os.Exit(run())
+}
+
+// run is separate from main so deferred calls happen before the process exits.
+func run() int {
 	config, err := utils.LoadConfig()
 	if err != nil {
 		panic(err)
@@ -36,7 +35,15 @@ func main() {
 		l.Logger.Error("Failed to check log size", "Error", logErr)
 	}
 	defer l.CloseLogger()
-	defer cleanupTempFiles()
+	launcher.TerminalCommand = config.Terminal.Command
+	launcher.RuntimeDir = config.Scripts.Dir
+	launcher.GracePeriod = config.Launch.GracePeriod
+	if config.Tmux.Split != "" {
+		launcher.TmuxSplit = config.Tmux.Split
+	}
+	if _, sweepErr := launcher.SweepScripts(config.Scripts.MaxAge); sweepErr != nil {
+		l.Logger.Error("Failed to remove stale scripts", "Error", sweepErr)
+	}
 	l.Logger.Info("Starting..")
-	cmd.Execute()
+	return cmd.Execute()
 }